@@ -2,27 +2,150 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/tailabs/mcp-milvus/internal/admin"
+	"github.com/tailabs/mcp-milvus/internal/audit"
+	"github.com/tailabs/mcp-milvus/internal/auth"
+	"github.com/tailabs/mcp-milvus/internal/config"
+	"github.com/tailabs/mcp-milvus/internal/confirm"
+	"github.com/tailabs/mcp-milvus/internal/health"
+	"github.com/tailabs/mcp-milvus/internal/metrics"
 	"github.com/tailabs/mcp-milvus/internal/middleware"
+	"github.com/tailabs/mcp-milvus/internal/policy"
+	"github.com/tailabs/mcp-milvus/internal/ratelimit"
 	"github.com/tailabs/mcp-milvus/internal/registry"
+	"github.com/tailabs/mcp-milvus/internal/resilience"
 	"github.com/tailabs/mcp-milvus/internal/session"
+	"github.com/tailabs/mcp-milvus/internal/session/store"
 	_ "github.com/tailabs/mcp-milvus/internal/tools"
+	"github.com/tailabs/mcp-milvus/internal/tracing"
 
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/sirupsen/logrus"
 )
 
+// version and commit are set by the Makefile through -ldflags
+var (
+	version = "dev"
+	commit  = "unknown"
+)
+
 func main() {
+	opts := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := config.Load(opts)
+	if err != nil {
+		logrus.Fatalf("Invalid configuration: %v", err)
+	}
+
+	if opts.PrintConfig {
+		fmt.Print(cfg.String())
+		return
+	}
+
 	// Initialize logging
-	logrus.SetLevel(logrus.InfoLevel)
-	logrus.SetFormatter(&logrus.JSONFormatter{
-		TimestampFormat: time.RFC3339,
+	level, _ := logrus.ParseLevel(cfg.Log.Level)
+	logrus.SetLevel(level)
+	if cfg.Log.Format == "text" {
+		logrus.SetFormatter(&logrus.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: time.RFC3339,
+		})
+	} else {
+		logrus.SetFormatter(&logrus.JSONFormatter{
+			TimestampFormat: time.RFC3339,
+		})
+	}
+
+	sessionStore, err := newSessionStore(cfg.Session)
+	if err != nil {
+		logrus.Fatalf("Failed to open session store: %v", err)
+	}
+	storeKey, err := cfg.Session.Redis.Key()
+	if err != nil {
+		logrus.Fatalf("Invalid session store: %v", err)
+	}
+	session.Configure(session.Options{
+		MaxSessions:         cfg.Session.MaxSessions,
+		DefaultTTL:          cfg.Session.TTL,
+		MaxConnections:      cfg.Session.MaxConnections,
+		HealthCheckInterval: cfg.Session.HealthCheckInterval,
+		NumCounters:         cfg.Session.NumCounters,
+		MaxCost:             cfg.Session.MaxCost,
+		BufferItems:         cfg.Session.BufferItems,
+		Store:               sessionStore,
+		StoreKey:            storeKey,
+	})
+	middleware.SetSlowCallThreshold(cfg.Server.SlowCallThreshold)
+	middleware.SetTimeouts(cfg.Calls.Timeout, cfg.Calls.ToolTimeouts)
+	if cfg.RateLimit.Enabled {
+		ratelimit.Configure(ratelimit.Options{
+			CallsPerSecond:          cfg.RateLimit.CallsPerSecond,
+			Burst:                   cfg.RateLimit.Burst,
+			ExpensiveCallsPerSecond: cfg.RateLimit.ExpensiveCallsPerSecond,
+			ExpensiveBurst:          cfg.RateLimit.ExpensiveBurst,
+			ExpensiveTools:          cfg.RateLimit.ExpensiveTools,
+			MaxConcurrent:           cfg.RateLimit.MaxConcurrent,
+		})
+	}
+	resilience.Configure(resilience.Options{
+		MaxAttempts:      cfg.Calls.Retry.MaxAttempts,
+		InitialBackoff:   cfg.Calls.Retry.InitialBackoff,
+		MaxBackoff:       cfg.Calls.Retry.MaxBackoff,
+		FailureThreshold: cfg.Calls.CircuitBreaker.FailureThreshold,
+		OpenTimeout:      cfg.Calls.CircuitBreaker.OpenTimeout,
 	})
 
+	shutdownTracing := func(context.Context) error { return nil }
+	if cfg.Tracing.Enabled {
+		shutdownTracing, err = tracing.Setup(context.Background(), tracing.Options{
+			Exporter:       cfg.Tracing.Exporter,
+			Endpoint:       cfg.Tracing.Endpoint,
+			Insecure:       cfg.Tracing.Insecure,
+			File:           cfg.Tracing.File,
+			SampleRatio:    cfg.Tracing.SampleRatio,
+			ServiceName:    cfg.Tracing.ServiceName,
+			ServiceVersion: version,
+		})
+		if err != nil {
+			logrus.Fatalf("Failed to setup tracing: %v", err)
+		}
+		logrus.WithField("exporter", cfg.Tracing.Exporter).Info("Tracing enabled")
+	}
+
+	profiles := make(map[string]session.ConnConfig, len(cfg.Connections.Profiles))
+	for name, profile := range cfg.Connections.Profiles {
+		profiles[name] = session.ConnConfig{
+			Address:        profile.Address,
+			Token:          profile.Credentials(),
+			DBName:         profile.DBName,
+			APIKey:         profile.APIKey,
+			EnableTLS:      profile.TLS.Enable,
+			CACertFile:     profile.TLS.CACertFile,
+			ClientCertFile: profile.TLS.ClientCertFile,
+			ClientKeyFile:  profile.TLS.ClientKeyFile,
+			ServerName:     profile.TLS.ServerName,
+		}
+	}
+	if err := session.SetProfiles(profiles, cfg.Connections.DefaultProfile, cfg.Connections.AllowCustom); err != nil {
+		logrus.Fatalf("Invalid connection profiles: %v", err)
+	}
+
+	engine, err := newPolicyEngine(cfg.Policies)
+	if err != nil {
+		logrus.Fatalf("Invalid policies: %v", err)
+	}
+	policy.SetEngine(engine)
+
 	// Setup session monitoring
 	session.RegisterSessionEventCallbacks()
 
@@ -30,44 +153,121 @@ func main() {
 	hooks := session.NewSessionAwareHooks()
 
 	// Create MCP server with enhanced features
-	s := server.NewMCPServer(
-		"mcp-milvus",
-		"0.1.0",
+	serverOpts := []server.ServerOption{
 		server.WithToolCapabilities(true),
 		server.WithResourceCapabilities(false, true),
 		server.WithPromptCapabilities(true),
 		server.WithRecovery(),
 		server.WithHooks(hooks),
-		server.WithToolHandlerMiddleware(middleware.Logging),
-		server.WithToolHandlerMiddleware(middleware.Auth),
-	)
+		server.WithToolFilter(middleware.FilterTools),
+	}
+	for _, m := range middleware.Chain() {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(m))
+	}
+	s := server.NewMCPServer("mcp-milvus", version, serverOpts...)
+
+	confirm.Configure(cfg.Confirmation.Enabled, cfg.Confirmation.TTL, sessionStore)
+
+	if err := setupAudit(cfg.Audit); err != nil {
+		logrus.Fatalf("Failed to setup audit log: %v", err)
+	}
 
 	// Register all Milvus tools using global registry
-	registry.RegisterAllTools(s)
+	if cfg.Server.ReadOnly {
+		logrus.WithField("tools", registry.ToolNames(registry.AccessRead)).Info("Read-only mode, mutating tools are not exposed")
+		registry.RegisterTools(s, registry.AccessRead)
+	} else {
+		registry.RegisterAllTools(s)
+	}
+
+	authenticator, err := newAuthenticator(cfg.Auth)
+	if err != nil {
+		logrus.Fatalf("Failed to setup authentication: %v", err)
+	}
+
+	healthOpts := health.Options{
+		Version:  version,
+		Commit:   commit,
+		Draining: middleware.Draining,
+	}
+	if cfg.Health.ProbeProfiles {
+		healthOpts.Probe = session.ProbeProfiles
+		healthOpts.ProbeTimeout = cfg.Health.ProbeTimeout
+		healthOpts.ProbeInterval = cfg.Health.ProbeInterval
+	}
+
+	transport, err := NewTransport(cfg.Server, s, authenticator, health.Handler(healthOpts))
+	if err != nil {
+		logrus.Fatalf("Failed to create transport: %v", err)
+	}
+
+	var adminServer *http.Server
+	if cfg.Admin.Enabled {
+		adminServer = startAdmin(cfg.Admin, authenticator)
+	}
+	var metricsServer *http.Server
+	if cfg.Metrics.Enabled {
+		metricsServer = startMetrics(cfg.Metrics)
+	}
 
 	// Setup graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
+	serveCtx, stopServe := context.WithCancel(context.Background())
+	defer stopServe()
+
 	// Start server in goroutine
+	serveErr := make(chan error, 1)
 	go func() {
-		logrus.Info("Starting MCP Milvus server...")
-
-		// Start the SSE server
-		sse := server.NewSSEServer(s)
-		if err := sse.Start(":8080"); err != nil {
-			logrus.Fatalf("Failed to start SSE server: %v", err)
-		}
+		logrus.WithFields(logrus.Fields{
+			"transport": cfg.Server.Transport,
+			"version":   version,
+			"commit":    commit,
+		}).Info("Starting MCP Milvus server...")
+		serveErr <- transport.Serve(serveCtx)
 	}()
 
-	// Wait for shutdown signal
-	<-sigChan
-	logrus.Info("Received shutdown signal, gracefully shutting down...")
+	// Wait for shutdown signal or for the transport to stop on its own,
+	// e.g. when a stdio client closes its end of the pipe
+	select {
+	case <-sigChan:
+		logrus.Info("Received shutdown signal, gracefully shutting down...")
+	case err := <-serveErr:
+		if err != nil {
+			logrus.WithError(err).Error("Transport stopped unexpectedly")
+		}
+		logrus.Info("Transport stopped, shutting down...")
+	}
 
 	// Graceful shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
+	// Fail readiness and refuse new tool calls, then let the running ones finish while
+	// their SSE streams are still open to deliver the results
+	middleware.StartDraining()
+	if running, err := middleware.WaitForCalls(ctx); err != nil {
+		logrus.WithField("tool_calls", running).Warn("Shutdown timeout, cutting off running tool calls")
+	} else {
+		logrus.Info("In-flight tool calls drained")
+	}
+
+	stopServe()
+	if err := transport.Shutdown(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to shutdown transport")
+	}
+	if adminServer != nil {
+		if err := adminServer.Shutdown(ctx); err != nil {
+			logrus.WithError(err).Error("Failed to shutdown admin server")
+		}
+	}
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			logrus.WithError(err).Error("Failed to shutdown metrics server")
+		}
+	}
+
 	// Close session manager and cleanup all connections
 	sessionManager := session.GetSessionManager()
 	totalSessions := sessionManager.Size()
@@ -77,12 +277,172 @@ func main() {
 		logrus.WithError(err).Error("Failed to close session manager")
 	}
 
-	// You could add server shutdown logic here if the server supports it
-	// For now, we'll just log and exit
-	select {
-	case <-ctx.Done():
+	if err := audit.GetAuditor().Close(); err != nil {
+		logrus.WithError(err).Error("Failed to close audit log")
+	}
+
+	// Flush the spans of the last calls
+	if err := shutdownTracing(ctx); err != nil {
+		logrus.WithError(err).Error("Failed to flush traces")
+	}
+
+	if ctx.Err() != nil {
 		logrus.Warn("Shutdown timeout")
-	default:
-		logrus.Info("Server shutdown successfully")
+		return
+	}
+	logrus.Info("Server shutdown successfully")
+}
+
+// startAdmin serves the operator endpoints on their own listener
+func startAdmin(cfg config.AdminConfig, authenticator auth.Authenticator) *http.Server {
+	if authenticator == nil {
+		logrus.Warn("Admin endpoints are enabled without authentication")
+	}
+
+	srv := &http.Server{
+		Addr:    cfg.Addr,
+		Handler: withAuth(authenticator, admin.Handler(session.GetSessionManager(), cfg.Role)),
+	}
+	go func() {
+		logrus.WithField("addr", cfg.Addr).Info("Serving admin endpoints")
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.WithError(err).Error("Admin server stopped")
+		}
+	}()
+	return srv
+}
+
+// startMetrics serves the Prometheus metrics on their own listener
+func startMetrics(cfg config.MetricsConfig) *http.Server {
+	metrics.RegisterActiveSessions(session.GetSessionManager().Size)
+
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Path, metrics.Handler())
+	srv := &http.Server{
+		Addr:    cfg.Addr,
+		Handler: mux,
+	}
+	go func() {
+		logrus.WithFields(logrus.Fields{"addr": cfg.Addr, "path": cfg.Path}).Info("Serving metrics")
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.WithError(err).Error("Metrics server stopped")
+		}
+	}()
+	return srv
+}
+
+// newSessionStore opens the shared session store, or returns nil to keep sessions in memory
+func newSessionStore(cfg config.SessionConfig) (store.Store, error) {
+	if cfg.Store != "redis" {
+		return nil, nil
+	}
+
+	redisStore, err := store.NewRedis(store.RedisOptions{
+		Address:   cfg.Redis.Address,
+		Username:  cfg.Redis.Username,
+		Password:  cfg.Redis.Password,
+		DB:        cfg.Redis.DB,
+		KeyPrefix: cfg.Redis.KeyPrefix,
+		TLS:       cfg.Redis.TLS,
+	})
+	if err != nil {
+		return nil, err
 	}
+	logrus.WithField("address", cfg.Redis.Address).Info("Sessions are shared through redis")
+	return redisStore, nil
+}
+
+// newAuthenticator builds the inbound authenticator from the auth config, or nil when auth is disabled
+func newAuthenticator(cfg config.AuthConfig) (auth.Authenticator, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	var chain auth.Chain
+	if len(cfg.Tokens) > 0 {
+		tokens := make([]auth.StaticToken, 0, len(cfg.Tokens))
+		for _, token := range cfg.Tokens {
+			tokens = append(tokens, auth.StaticToken{Name: token.Name, Token: token.Token, Roles: token.Roles})
+		}
+		static, err := auth.NewStaticAuthenticator(tokens)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, static)
+	}
+	if cfg.JWT.JWKSFile != "" {
+		jwtAuth, err := auth.NewJWTAuthenticator(auth.JWTOptions{
+			JWKSFile:     cfg.JWT.JWKSFile,
+			Issuer:       cfg.JWT.Issuer,
+			Audience:     cfg.JWT.Audience,
+			SubjectClaim: cfg.JWT.SubjectClaim,
+			RolesClaim:   cfg.JWT.RolesClaim,
+			Leeway:       cfg.JWT.Leeway,
+		})
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, jwtAuth)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"static_tokens": len(cfg.Tokens),
+		"jwks_file":     cfg.JWT.JWKSFile,
+	}).Info("Inbound authentication enabled")
+	return chain, nil
+}
+
+// newPolicyEngine builds the tool authorization engine from the policies config
+func newPolicyEngine(cfg config.PoliciesConfig) (*policy.Engine, error) {
+	policies := make(map[string]*policy.Policy, len(cfg.Definitions))
+	for name, def := range cfg.Definitions {
+		p := &policy.Policy{
+			AllowTools:  def.AllowTools,
+			DenyTools:   def.DenyTools,
+			Databases:   def.Databases,
+			Collections: def.Collections,
+		}
+		if def.Preset != "" {
+			preset, ok := policy.Preset(def.Preset)
+			if !ok {
+				return nil, fmt.Errorf("policy %s: unknown preset %s", name, def.Preset)
+			}
+			p.AllowTools = append(preset.AllowTools, def.AllowTools...)
+		}
+		policies[name] = p
+	}
+
+	return policy.NewEngine(policy.Config{
+		Policies:   policies,
+		Principals: cfg.Principals,
+		Roles:      cfg.Roles,
+		Profiles:   cfg.Profiles,
+		Default:    cfg.Default,
+	})
+}
+
+// setupAudit registers the configured audit sinks
+func setupAudit(cfg config.AuditConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	auditor := audit.GetAuditor()
+	auditor.SetMaxArgumentLength(cfg.MaxArgumentLength)
+	if cfg.File != "" {
+		sink, err := audit.NewFileSink(cfg.File)
+		if err != nil {
+			return err
+		}
+		auditor.AddSink(sink)
+	}
+	if cfg.Log {
+		auditor.AddSink(audit.LogSink{})
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"file": cfg.File,
+		"log":  cfg.Log,
+	}).Info("Audit log enabled")
+	return nil
 }
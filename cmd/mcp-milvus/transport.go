@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/tailabs/mcp-milvus/internal/auth"
+	"github.com/tailabs/mcp-milvus/internal/config"
+	"github.com/tailabs/mcp-milvus/internal/health"
+	"github.com/tailabs/mcp-milvus/internal/session"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	transportStdio          = "stdio"
+	transportSSE            = "sse"
+	transportStreamableHTTP = "streamable-http"
+)
+
+// Transport serves an MCP server over a specific wire protocol
+type Transport interface {
+	// Serve blocks until the transport stops, the client goes away or ctx is cancelled
+	Serve(ctx context.Context) error
+	// Shutdown stops the transport and releases its listeners
+	Shutdown(ctx context.Context) error
+}
+
+// NewTransport creates the transport selected in the server config
+// A nil authenticator leaves the HTTP based transports unauthenticated, the health
+// endpoints are served by them without authentication
+func NewTransport(cfg config.ServerConfig, s *server.MCPServer, authenticator auth.Authenticator, healthHandler http.Handler) (Transport, error) {
+	switch cfg.Transport {
+	case transportStdio:
+		return newStdioTransport(s), nil
+	case transportSSE:
+		return newSSETransport(s, cfg.Addr, authenticator, healthHandler), nil
+	case transportStreamableHTTP:
+		return newStreamableHTTPTransport(s, cfg.Addr, cfg.HeartbeatInterval, authenticator, healthHandler), nil
+	default:
+		return nil, fmt.Errorf("unsupported transport: %s (available: %s, %s, %s)",
+			cfg.Transport, transportStdio, transportSSE, transportStreamableHTTP)
+	}
+}
+
+// stdioTransport serves a single implicit client over stdin/stdout
+type stdioTransport struct {
+	stdio *server.StdioServer
+}
+
+func newStdioTransport(s *server.MCPServer) *stdioTransport {
+	stdio := server.NewStdioServer(s)
+	// stdout carries the protocol, so errors must never be written there
+	stdio.SetErrorLogger(log.New(logrus.StandardLogger().WriterLevel(logrus.ErrorLevel), "", 0))
+	return &stdioTransport{stdio: stdio}
+}
+
+func (t *stdioTransport) Serve(ctx context.Context) error {
+	logrus.Info("Serving MCP over stdio")
+	err := t.stdio.Listen(ctx, os.Stdin, os.Stdout)
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown is a no-op, stdio stops as soon as the serve context is cancelled
+func (t *stdioTransport) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// sseTransport serves clients over HTTP with Server-Sent Events
+type sseTransport struct {
+	sse  *server.SSEServer
+	addr string
+}
+
+func newSSETransport(s *server.MCPServer, addr string, authenticator auth.Authenticator, healthHandler http.Handler) *sseTransport {
+	srv := &http.Server{Addr: addr}
+	sse := server.NewSSEServer(s, server.WithHTTPServer(srv))
+	srv.Handler = withHealth(healthHandler, withAuth(authenticator, sse))
+	return &sseTransport{
+		sse:  sse,
+		addr: addr,
+	}
+}
+
+func (t *sseTransport) Serve(ctx context.Context) error {
+	logrus.WithField("addr", t.addr).Info("Serving MCP over SSE")
+	err := t.sse.Start(t.addr)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown ends the SSE streams and stops the listener, in-flight tool calls must be
+// drained before, their results can no longer be delivered afterwards
+func (t *sseTransport) Shutdown(ctx context.Context) error {
+	return t.sse.Shutdown(ctx)
+}
+
+// streamableHTTPTransport serves clients over the MCP streamable HTTP transport,
+// which works with plain request/response POSTs and needs no long-lived stream
+type streamableHTTPTransport struct {
+	streamable *server.StreamableHTTPServer
+	addr       string
+}
+
+func newStreamableHTTPTransport(s *server.MCPServer, addr string, heartbeat time.Duration, authenticator auth.Authenticator, healthHandler http.Handler) *streamableHTTPTransport {
+	srv := &http.Server{Addr: addr}
+	streamable := server.NewStreamableHTTPServer(s,
+		server.WithSessionIdManager(session.NewStreamableSessionIdManager()),
+		server.WithHeartbeatInterval(heartbeat),
+		server.WithStreamableHTTPServer(srv),
+	)
+	srv.Handler = withHealth(healthHandler, withAuth(authenticator, streamable))
+	return &streamableHTTPTransport{
+		streamable: streamable,
+		addr:       addr,
+	}
+}
+
+func (t *streamableHTTPTransport) Serve(ctx context.Context) error {
+	logrus.WithField("addr", t.addr).Info("Serving MCP over streamable HTTP")
+	err := t.streamable.Start(t.addr)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func (t *streamableHTTPTransport) Shutdown(ctx context.Context) error {
+	return t.streamable.Shutdown(ctx)
+}
+
+// withHealth routes the health endpoints around authentication, so probes need no token
+func withHealth(healthHandler http.Handler, next http.Handler) http.Handler {
+	if healthHandler == nil {
+		return next
+	}
+	mux := http.NewServeMux()
+	for _, path := range health.Paths {
+		mux.Handle(path, healthHandler)
+	}
+	mux.Handle("/", next)
+	return mux
+}
+
+// withAuth requires a valid bearer token on every request when an authenticator is configured
+func withAuth(authenticator auth.Authenticator, handler http.Handler) http.Handler {
+	if authenticator == nil {
+		return handler
+	}
+	return auth.Middleware(authenticator, handler)
+}
@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tailabs/mcp-milvus/internal/auth"
+	"github.com/tailabs/mcp-milvus/internal/session"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Handler serves the operator endpoints on top of the session manager:
+//
+//	GET    /admin/sessions       lists the sessions, of all replicas with a shared store
+//	DELETE /admin/sessions/{id}  disconnects a session
+//
+// With inbound auth enabled, callers need role; without it the endpoints are open,
+// so the admin listener should only be reachable by operators
+func Handler(sessions session.SessionManagerInterface, role string) http.Handler {
+	h := &handler{sessions: sessions}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/sessions", h.listSessions)
+	mux.HandleFunc("DELETE /admin/sessions/{id}", h.disconnectSession)
+	return requireRole(role, mux)
+}
+
+type handler struct {
+	sessions session.SessionManagerInterface
+}
+
+func (h *handler) listSessions(w http.ResponseWriter, r *http.Request) {
+	states := h.sessions.List()
+	infos := make([]session.SessionInfo, 0, len(states))
+	for _, state := range states {
+		infos = append(infos, state.Info())
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"sessions": infos})
+}
+
+func (h *handler) disconnectSession(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("id")
+	if _, err := h.sessions.GetState(sessionID); err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := h.sessions.Disconnect(sessionID); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	fields := logrus.Fields{"session_id": sessionID}
+	if principal := auth.PrincipalFromContext(r.Context()); principal != nil {
+		fields["operator"] = principal.Subject
+	}
+	logrus.WithFields(fields).Warn("Session disconnected through admin API")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requireRole rejects authenticated callers without role
+func requireRole(role string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if principal := auth.PrincipalFromContext(r.Context()); principal != nil && !principal.HasRole(role) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "role " + role + " required"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logrus.WithError(err).Warn("Failed to write admin response")
+	}
+}
@@ -0,0 +1,99 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tailabs/mcp-milvus/internal/auth"
+	"github.com/tailabs/mcp-milvus/internal/session"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSessions implements the parts of the session manager used by the admin API
+type fakeSessions struct {
+	session.SessionManagerInterface
+	states       map[string]*session.SessionState
+	disconnected []string
+}
+
+func (f *fakeSessions) List() []*session.SessionState {
+	var states []*session.SessionState
+	for _, state := range f.states {
+		states = append(states, state)
+	}
+	return states
+}
+
+func (f *fakeSessions) GetState(sessionID string) (*session.SessionState, error) {
+	state, ok := f.states[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("session not found: %s", sessionID)
+	}
+	return state, nil
+}
+
+func (f *fakeSessions) Disconnect(sessionID string) error {
+	delete(f.states, sessionID)
+	f.disconnected = append(f.disconnected, sessionID)
+	return nil
+}
+
+func newFakeSessions() *fakeSessions {
+	return &fakeSessions{states: map[string]*session.SessionState{
+		"s1": {
+			SessionID:   "s1",
+			Principal:   "alice",
+			ConnConfig:  &session.ConnConfig{Address: "milvus:19530", Token: "root:Milvus", DBName: "analytics"},
+			CreatedAt:   time.Now(),
+			AccessCount: 7,
+		},
+	}}
+}
+
+func serve(h http.Handler, method, path string, principal *auth.Principal) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if principal != nil {
+		req = req.WithContext(auth.WithPrincipal(req.Context(), principal))
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestListSessions(t *testing.T) {
+	h := Handler(newFakeSessions(), "admin")
+
+	rec := serve(h, http.MethodGet, "/admin/sessions", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "Milvus", "credentials are never listed")
+
+	var body struct {
+		Sessions []session.SessionInfo `json:"sessions"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Sessions, 1)
+	assert.Equal(t, "analytics", body.Sessions[0].Database)
+	assert.Equal(t, int64(7), body.Sessions[0].AccessCount)
+}
+
+func TestDisconnectSession(t *testing.T) {
+	sessions := newFakeSessions()
+	h := Handler(sessions, "admin")
+
+	rec := serve(h, http.MethodDelete, "/admin/sessions/s1", &auth.Principal{Subject: "bob"})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, sessions.disconnected)
+
+	rec = serve(h, http.MethodDelete, "/admin/sessions/s1", &auth.Principal{Subject: "ops", Roles: []string{"admin"}})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, []string{"s1"}, sessions.disconnected)
+
+	rec = serve(h, http.MethodDelete, "/admin/sessions/s1", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
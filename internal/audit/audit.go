@@ -0,0 +1,201 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+const redactedValue = "******"
+
+// sensitiveArguments are redacted from the recorded arguments, matched case-insensitively as substrings
+var sensitiveArguments = []string{"token", "password", "api_key", "secret"}
+
+// Event is a single audited tool call
+type Event struct {
+	Time       time.Time      `json:"time"`
+	Principal  string         `json:"principal,omitempty"`
+	SessionID  string         `json:"session_id"`
+	Address    string         `json:"address,omitempty"`
+	Database   string         `json:"database,omitempty"`
+	Profile    string         `json:"profile,omitempty"`
+	Tool       string         `json:"tool"`
+	Arguments  map[string]any `json:"arguments,omitempty"`
+	Collection string         `json:"collection,omitempty"`
+	// ResultSize is the size of the result content in bytes
+	ResultSize int    `json:"result_size"`
+	DurationMs int64  `json:"duration_ms"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Sink persists audit events
+type Sink interface {
+	Write(event *Event) error
+	Close() error
+}
+
+// Auditor redacts events and fans them out to the registered sinks
+type Auditor struct {
+	mu                sync.RWMutex
+	sinks             []Sink
+	maxArgumentLength int
+}
+
+var auditor = &Auditor{}
+
+// GetAuditor returns the global auditor
+func GetAuditor() *Auditor {
+	return auditor
+}
+
+// SetMaxArgumentLength truncates recorded string arguments longer than n bytes and
+// summarizes other arguments whose JSON is longer, 0 keeps them whole
+func (a *Auditor) SetMaxArgumentLength(n int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.maxArgumentLength = n
+}
+
+// AddSink registers a sink, every event is written to all sinks
+func (a *Auditor) AddSink(sink Sink) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sinks = append(a.sinks, sink)
+}
+
+// Enabled reports whether any sink is registered
+func (a *Auditor) Enabled() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return len(a.sinks) > 0
+}
+
+// Record redacts the event arguments and writes the event to every sink
+// Sink failures are logged and never fail the tool call
+func (a *Auditor) Record(event *Event) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if len(a.sinks) == 0 {
+		return
+	}
+
+	event.Arguments = redactArguments(event.Arguments, a.maxArgumentLength)
+	for _, sink := range a.sinks {
+		if err := sink.Write(event); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"tool":    event.Tool,
+				"session": event.SessionID,
+				"error":   err,
+			}).Error("Failed to write audit event")
+		}
+	}
+}
+
+// Close closes all sinks
+func (a *Auditor) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var errs []string
+	for _, sink := range a.sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	a.sinks = nil
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to close audit sinks: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// redactArguments returns a copy of args with secrets masked at any depth, long strings
+// truncated and other values larger than maxLength once serialized replaced by a summary
+func redactArguments(args map[string]any, maxLength int) map[string]any {
+	if len(args) == 0 {
+		return nil
+	}
+
+	redacted := make(map[string]any, len(args))
+	for k, v := range args {
+		if isSensitive(k) {
+			redacted[k] = redactedValue
+			continue
+		}
+		v = redactValue(v)
+		if maxLength > 0 {
+			v = capArgument(v, maxLength)
+		}
+		redacted[k] = v
+	}
+	return redacted
+}
+
+// redactValue masks the secrets nested in objects and arrays, e.g. params.token
+func redactValue(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(value))
+		for k, nested := range value {
+			if isSensitive(k) {
+				redacted[k] = redactedValue
+			} else {
+				redacted[k] = redactValue(nested)
+			}
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(value))
+		for i, nested := range value {
+			redacted[i] = redactValue(nested)
+		}
+		return redacted
+	}
+	return v
+}
+
+// capArgument truncates a string or summarizes any other value whose JSON is longer than maxLength
+func capArgument(v any, maxLength int) any {
+	if s, ok := v.(string); ok {
+		if len(s) > maxLength {
+			// Cut on a rune boundary so the sink never gets invalid UTF-8
+			cut := maxLength
+			for cut > 0 && !utf8.RuneStart(s[cut]) {
+				cut--
+			}
+			return fmt.Sprintf("%s...(%d bytes)", s[:cut], len(s))
+		}
+		return s
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("(%T)", v)
+	}
+	if len(data) <= maxLength {
+		return v
+	}
+	switch value := v.(type) {
+	case []any:
+		return fmt.Sprintf("(array of %d items, %d bytes)", len(value), len(data))
+	case map[string]any:
+		return fmt.Sprintf("(object with %d keys, %d bytes)", len(value), len(data))
+	}
+	return fmt.Sprintf("(%d bytes)", len(data))
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveArguments {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memorySink struct {
+	events []Event
+	err    error
+}
+
+func (s *memorySink) Write(event *Event) error {
+	s.events = append(s.events, *event)
+	return s.err
+}
+
+func (s *memorySink) Close() error { return nil }
+
+func TestRedactArguments(t *testing.T) {
+	args := map[string]any{
+		"collection_name":    "docs",
+		"token":              "user:pass",
+		"api_key":            "key",
+		"confirmation_token": "abc",
+		"data":               strings.Repeat("x", 20),
+		"limit":              10,
+		"vectors":            []any{[]any{0.1, 0.2}, []any{0.3, 0.4}},
+		"rows":               map[string]any{"id": []any{1, 2}, "text": []any{"a", "b"}},
+		"ids":                []any{1},
+	}
+
+	redacted := redactArguments(args, 8)
+	assert.Equal(t, "docs", redacted["collection_name"])
+	assert.Equal(t, redactedValue, redacted["token"])
+	assert.Equal(t, redactedValue, redacted["api_key"])
+	assert.Equal(t, redactedValue, redacted["confirmation_token"])
+	assert.Equal(t, "xxxxxxxx...(20 bytes)", redacted["data"])
+	assert.Equal(t, 10, redacted["limit"])
+	assert.Equal(t, "(array of 2 items, 21 bytes)", redacted["vectors"])
+	assert.Equal(t, "(object with 2 keys, 29 bytes)", redacted["rows"])
+	assert.Equal(t, []any{1}, redacted["ids"], "small values are kept")
+	assert.Equal(t, "user:pass", args["token"], "the original arguments must not be modified")
+
+	assert.Nil(t, redactArguments(nil, 8))
+}
+
+func TestRedactNestedSecrets(t *testing.T) {
+	args := map[string]any{
+		"params":   map[string]any{"nprobe": 16, "token": "user:pass"},
+		"requests": []any{map[string]any{"Password": "secret"}},
+	}
+
+	redacted := redactArguments(args, 0)
+	assert.Equal(t, map[string]any{"nprobe": 16, "token": redactedValue}, redacted["params"])
+	assert.Equal(t, []any{map[string]any{"Password": redactedValue}}, redacted["requests"])
+	assert.Equal(t, "user:pass", args["params"].(map[string]any)["token"], "the original arguments must not be modified")
+
+	// A secret is masked before its object is summarized, so it never counts towards the summary
+	capped := redactArguments(map[string]any{"params": map[string]any{"api_key": strings.Repeat("k", 100)}}, 40)
+	assert.Equal(t, map[string]any{"api_key": redactedValue}, capped["params"])
+}
+
+func TestTruncateOnRuneBoundary(t *testing.T) {
+	redacted := redactArguments(map[string]any{"text": "ab向量检索"}, 4)
+	text := redacted["text"].(string)
+	assert.True(t, utf8.ValidString(text))
+	assert.Equal(t, "ab...(14 bytes)", text)
+}
+
+func TestAuditor(t *testing.T) {
+	a := &Auditor{}
+	assert.False(t, a.Enabled())
+	a.Record(&Event{Tool: "milvus_query"}) // no sinks, no panic
+
+	failing := &memorySink{err: errors.New("disk full")}
+	working := &memorySink{}
+	a.AddSink(failing)
+	a.AddSink(working)
+	assert.True(t, a.Enabled())
+
+	a.Record(&Event{Tool: "milvus_connector", Arguments: map[string]any{"password": "secret"}})
+	require.Len(t, working.events, 1, "a failing sink must not stop the others")
+	assert.Equal(t, redactedValue, working.events[0].Arguments["password"])
+
+	require.NoError(t, a.Close())
+	assert.False(t, a.Enabled())
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	for i := 0; i < 2; i++ {
+		// Reopening appends instead of truncating
+		sink, err := NewFileSink(path)
+		require.NoError(t, err)
+		require.NoError(t, sink.Write(&Event{
+			Time:       time.Now(),
+			Principal:  "alice",
+			SessionID:  "s1",
+			Tool:       "milvus_delete_entities",
+			Collection: "docs",
+			Arguments:  map[string]any{"filter_expr": "id > 0"},
+			Success:    true,
+		}))
+		require.NoError(t, sink.Close())
+	}
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var lines int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		assert.Equal(t, "alice", event.Principal)
+		assert.Equal(t, "docs", event.Collection)
+		lines++
+	}
+	assert.Equal(t, 2, lines)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// JSONLSink appends one JSON document per event to a writer
+type JSONLSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewJSONLSink writes events to w, closing it on Close if it is an io.Closer
+func NewJSONLSink(w io.Writer) *JSONLSink {
+	sink := &JSONLSink{w: w}
+	if closer, ok := w.(io.Closer); ok {
+		sink.closer = closer
+	}
+	return sink
+}
+
+// NewFileSink opens path in append-only mode, creating it if needed
+func NewFileSink(path string) (*JSONLSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return NewJSONLSink(f), nil
+}
+
+func (s *JSONLSink) Write(event *Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// A single write per event keeps lines intact when several processes share the file
+	_, err = s.w.Write(line)
+	return err
+}
+
+func (s *JSONLSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// LogSink writes events to the server log
+type LogSink struct{}
+
+func (LogSink) Write(event *Event) error {
+	logrus.WithFields(logrus.Fields{
+		"audit":       true,
+		"principal":   event.Principal,
+		"session":     event.SessionID,
+		"address":     event.Address,
+		"database":    event.Database,
+		"tool":        event.Tool,
+		"arguments":   event.Arguments,
+		"collection":  event.Collection,
+		"result_size": event.ResultSize,
+		"duration_ms": event.DurationMs,
+		"success":     event.Success,
+		"error":       event.Error,
+	}).Info("Audit")
+	return nil
+}
+
+func (LogSink) Close() error {
+	return nil
+}
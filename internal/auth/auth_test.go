@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var hmacSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func writeJWKS(t *testing.T, rsaKey *rsa.PublicKey) string {
+	t.Helper()
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "rsa-1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "oct",
+				"kid": "hmac-1",
+				"alg": "HS256",
+				"k":   base64.RawURLEncoding.EncodeToString(hmacSecret),
+			},
+			{
+				// Encryption keys are ignored
+				"kty": "oct",
+				"kid": "enc-1",
+				"use": "enc",
+				"k":   base64.RawURLEncoding.EncodeToString([]byte("unused")),
+			},
+		},
+	}
+	data, err := json.Marshal(jwks)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestStaticAuthenticator(t *testing.T) {
+	a, err := NewStaticAuthenticator([]StaticToken{
+		{Name: "ci", Token: "ci-secret", Roles: []string{"reader"}},
+		{Name: "admin", Token: "admin-secret"},
+	})
+	require.NoError(t, err)
+
+	principal, err := a.Authenticate(context.Background(), "ci-secret")
+	require.NoError(t, err)
+	assert.Equal(t, "ci", principal.Subject)
+	assert.Equal(t, MethodToken, principal.Method)
+	assert.True(t, principal.HasRole("reader"))
+
+	_, err = a.Authenticate(context.Background(), "wrong")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = NewStaticAuthenticator([]StaticToken{{Name: "a", Token: "x"}, {Name: "b", Token: "x"}})
+	assert.Error(t, err, "duplicate token values are ambiguous")
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	a, err := NewJWTAuthenticator(JWTOptions{
+		JWKSFile: writeJWKS(t, &rsaKey.PublicKey),
+		Issuer:   "https://issuer.example.com",
+		Audience: "mcp-milvus",
+	})
+	require.NoError(t, err)
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":   "alice",
+			"iss":   "https://issuer.example.com",
+			"aud":   "mcp-milvus",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"roles": []string{"reader", "writer"},
+		}
+	}
+
+	t.Run("rsa", func(t *testing.T) {
+		principal, err := a.Authenticate(context.Background(), sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, valid()))
+		require.NoError(t, err)
+		assert.Equal(t, "alice", principal.Subject)
+		assert.Equal(t, MethodJWT, principal.Method)
+		assert.Equal(t, []string{"reader", "writer"}, principal.Roles)
+	})
+
+	t.Run("hmac without kid", func(t *testing.T) {
+		claims := valid()
+		claims["roles"] = "reader writer"
+		principal, err := a.Authenticate(context.Background(), sign(t, jwt.SigningMethodHS256, "", hmacSecret, claims))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"reader", "writer"}, principal.Roles)
+	})
+
+	rejected := []struct {
+		name  string
+		token func() string
+	}{
+		{"expired", func() string {
+			claims := valid()
+			claims["exp"] = time.Now().Add(-time.Hour).Unix()
+			return sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims)
+		}},
+		{"missing exp", func() string {
+			claims := valid()
+			delete(claims, "exp")
+			return sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims)
+		}},
+		{"wrong issuer", func() string {
+			claims := valid()
+			claims["iss"] = "https://evil.example.com"
+			return sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims)
+		}},
+		{"wrong audience", func() string {
+			claims := valid()
+			claims["aud"] = "other"
+			return sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims)
+		}},
+		{"missing subject", func() string {
+			claims := valid()
+			delete(claims, "sub")
+			return sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims)
+		}},
+		{"unknown kid", func() string {
+			return sign(t, jwt.SigningMethodRS256, "rsa-2", rsaKey, valid())
+		}},
+		{"algorithm not matching the key", func() string {
+			return sign(t, jwt.SigningMethodHS384, "hmac-1", hmacSecret, valid())
+		}},
+		{"encryption key", func() string {
+			return sign(t, jwt.SigningMethodHS256, "enc-1", []byte("unused"), valid())
+		}},
+		{"none", func() string {
+			return sign(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, valid())
+		}},
+	}
+	for _, tt := range rejected {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := a.Authenticate(context.Background(), tt.token())
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestLoadJWKSErrors(t *testing.T) {
+	_, err := NewJWTAuthenticator(JWTOptions{JWKSFile: filepath.Join(t.TempDir(), "missing.json")})
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys":[{"kty":"EC","crv":"P-256","x":"AQ","y":"AQ"}]}`), 0o600))
+	_, err = NewJWTAuthenticator(JWTOptions{JWKSFile: path})
+	assert.Error(t, err, "points off the curve are rejected")
+}
+
+func TestMiddleware(t *testing.T) {
+	a, err := NewStaticAuthenticator([]StaticToken{{Name: "ci", Token: "ci-secret"}})
+	require.NoError(t, err)
+
+	var got *Principal
+	handler := Middleware(a, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = PrincipalFromContext(r.Context())
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"missing header", "", http.StatusUnauthorized},
+		{"wrong scheme", "Basic Y2k6Y2k=", http.StatusUnauthorized},
+		{"invalid token", "Bearer nope", http.StatusUnauthorized},
+		{"valid token", "Bearer ci-secret", http.StatusOK},
+		{"case insensitive scheme", "bearer ci-secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			req := httptest.NewRequest(http.MethodGet, "/sse", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status == http.StatusOK {
+				require.NotNil(t, got)
+				assert.Equal(t, "ci", got.Subject)
+			} else {
+				assert.Nil(t, got)
+				assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
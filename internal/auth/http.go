@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Middleware rejects HTTP requests without a valid bearer token and binds the
+// authenticated principal to the request context, which the MCP server passes on
+// to session hooks and tool handlers
+func Middleware(authenticator Authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			unauthorized(w, ErrMissingToken)
+			return
+		}
+
+		principal, err := authenticator.Authenticate(r.Context(), token)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"remote_addr": r.RemoteAddr,
+				"path":        r.URL.Path,
+				"error":       err,
+			}).Warn("Rejected unauthenticated request")
+			unauthorized(w, ErrInvalidToken)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func unauthorized(w http.ResponseWriter, err error) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="mcp-milvus"`)
+	http.Error(w, err.Error(), http.StatusUnauthorized)
+}
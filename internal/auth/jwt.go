@@ -0,0 +1,282 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// validMethods lists the signing algorithms accepted in JWT headers, "none" is never accepted
+var validMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"HS256", "HS384", "HS512",
+	"EdDSA",
+}
+
+// JWTOptions configures JWT validation
+type JWTOptions struct {
+	// JWKSFile is a local JSON Web Key Set holding the verification keys
+	JWKSFile string
+	// Issuer and Audience are checked against the iss and aud claims when set
+	Issuer   string
+	Audience string
+	// SubjectClaim names the claim identifying the caller, defaults to "sub"
+	SubjectClaim string
+	// RolesClaim names the claim listing the caller's roles, defaults to "roles"
+	// Both JSON arrays and space separated strings (as in "scope") are accepted
+	RolesClaim string
+	// Leeway allows for clock skew when checking exp, nbf and iat
+	Leeway time.Duration
+}
+
+// JWTAuthenticator validates signed JWTs against a local JSON Web Key Set
+type JWTAuthenticator struct {
+	keys   []verificationKey
+	opts   JWTOptions
+	parser *jwt.Parser
+}
+
+type verificationKey struct {
+	id  string
+	alg string
+	key interface{}
+}
+
+// NewJWTAuthenticator loads the JWKS file and creates a JWT authenticator
+func NewJWTAuthenticator(opts JWTOptions) (*JWTAuthenticator, error) {
+	if opts.SubjectClaim == "" {
+		opts.SubjectClaim = "sub"
+	}
+	if opts.RolesClaim == "" {
+		opts.RolesClaim = "roles"
+	}
+
+	keys, err := loadJWKS(opts.JWKSFile)
+	if err != nil {
+		return nil, err
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods(validMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(opts.Leeway),
+	}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+
+	return &JWTAuthenticator{
+		keys:   keys,
+		opts:   opts,
+		parser: jwt.NewParser(parserOpts...),
+	}, nil
+}
+
+// Authenticate verifies the token signature and claims
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	if _, err := a.parser.ParseWithClaims(token, claims, a.keyFunc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	subject, _ := claims[a.opts.SubjectClaim].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("%w: missing %s claim", ErrInvalidToken, a.opts.SubjectClaim)
+	}
+
+	return &Principal{
+		Subject: subject,
+		Method:  MethodJWT,
+		Roles:   rolesFromClaim(claims[a.opts.RolesClaim]),
+		Claims:  claims,
+	}, nil
+}
+
+// keyFunc selects the keys matching the token's kid and algorithm
+func (a *JWTAuthenticator) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	var keys []jwt.VerificationKey
+	for _, k := range a.keys {
+		if kid != "" && k.id != kid {
+			continue
+		}
+		if !k.accepts(token.Method) {
+			continue
+		}
+		keys = append(keys, k.key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no verification key for kid %q and alg %s", kid, token.Method.Alg())
+	}
+	return jwt.VerificationKeySet{Keys: keys}, nil
+}
+
+// accepts reports whether the key may verify tokens signed with method
+func (k verificationKey) accepts(method jwt.SigningMethod) bool {
+	if k.alg != "" {
+		return k.alg == method.Alg()
+	}
+	switch k.key.(type) {
+	case *rsa.PublicKey:
+		_, rsaMethod := method.(*jwt.SigningMethodRSA)
+		_, pssMethod := method.(*jwt.SigningMethodRSAPSS)
+		return rsaMethod || pssMethod
+	case *ecdsa.PublicKey:
+		_, ok := method.(*jwt.SigningMethodECDSA)
+		return ok
+	case ed25519.PublicKey:
+		_, ok := method.(*jwt.SigningMethodEd25519)
+		return ok
+	case []byte:
+		_, ok := method.(*jwt.SigningMethodHMAC)
+		return ok
+	}
+	return false
+}
+
+func rolesFromClaim(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		roles := make([]string, 0, len(v))
+		for _, r := range v {
+			if s, ok := r.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+		return roles
+	}
+	return nil
+}
+
+// jsonWebKey is the subset of RFC 7517 needed for signature verification
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+func loadJWKS(path string) ([]verificationKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS file %s: %w", path, err)
+	}
+
+	keys := make([]verificationKey, 0, len(set.Keys))
+	for i, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d (kid %q) in %s: %w", i, jwk.Kid, path, err)
+		}
+		keys = append(keys, verificationKey{id: jwk.Kid, alg: jwk.Alg, key: key})
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no signature keys found in JWKS file %s", path)
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		if !e.IsInt64() {
+			return nil, fmt.Errorf("exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key")
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil || len(secret) == 0 {
+			return nil, fmt.Errorf("invalid symmetric key")
+		}
+		return secret, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
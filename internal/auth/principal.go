@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	MethodToken = "token"
+	MethodJWT   = "jwt"
+)
+
+var (
+	ErrMissingToken = errors.New("missing bearer token")
+	ErrInvalidToken = errors.New("invalid bearer token")
+)
+
+// Principal is the authenticated identity behind an MCP client
+type Principal struct {
+	// Subject uniquely identifies the caller, e.g. the token name or the JWT subject
+	Subject string `json:"subject"`
+	// Method is the authentication method that produced the principal
+	Method string   `json:"method"`
+	Roles  []string `json:"roles,omitempty"`
+	// Claims holds the verified JWT claims, it is nil for static tokens
+	Claims map[string]interface{} `json:"-"`
+}
+
+// HasRole reports whether the principal was granted the given role
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator verifies a bearer token and returns the principal it belongs to
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Principal, error)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal bound to ctx, or nil for unauthenticated
+// transports such as stdio
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+)
+
+// StaticToken is a long-lived bearer token issued to a named client
+type StaticToken struct {
+	Name  string
+	Token string
+	Roles []string
+}
+
+// StaticAuthenticator accepts a fixed list of bearer tokens
+type StaticAuthenticator struct {
+	tokens []staticEntry
+}
+
+type staticEntry struct {
+	digest    [sha256.Size]byte
+	principal Principal
+}
+
+// NewStaticAuthenticator creates an authenticator for the given tokens
+func NewStaticAuthenticator(tokens []StaticToken) (*StaticAuthenticator, error) {
+	a := &StaticAuthenticator{tokens: make([]staticEntry, 0, len(tokens))}
+	seen := make(map[[sha256.Size]byte]string, len(tokens))
+	for _, t := range tokens {
+		if t.Name == "" || t.Token == "" {
+			return nil, fmt.Errorf("static tokens need both a name and a token")
+		}
+		digest := sha256.Sum256([]byte(t.Token))
+		if other, ok := seen[digest]; ok {
+			return nil, fmt.Errorf("static tokens %s and %s share the same value", other, t.Name)
+		}
+		seen[digest] = t.Name
+		a.tokens = append(a.tokens, staticEntry{
+			digest:    digest,
+			principal: Principal{Subject: t.Name, Method: MethodToken, Roles: t.Roles},
+		})
+	}
+	return a, nil
+}
+
+// Authenticate compares the token against every configured token in constant time
+func (a *StaticAuthenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	digest := sha256.Sum256([]byte(token))
+	var match *staticEntry
+	for i := range a.tokens {
+		if subtle.ConstantTimeCompare(digest[:], a.tokens[i].digest[:]) == 1 {
+			match = &a.tokens[i]
+		}
+	}
+	if match == nil {
+		return nil, ErrInvalidToken
+	}
+	principal := match.principal
+	return &principal, nil
+}
+
+// Chain tries each authenticator in order and returns the first principal
+type Chain []Authenticator
+
+func (c Chain) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	err := ErrInvalidToken
+	for _, a := range c {
+		principal, authErr := a.Authenticate(ctx, token)
+		if authErr == nil {
+			return principal, nil
+		}
+		err = authErr
+	}
+	return nil, err
+}
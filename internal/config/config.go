@@ -0,0 +1,920 @@
+package config
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is the prefix of every environment variable read by the server
+const EnvPrefix = "MCP_MILVUS_"
+
+const redactedSecret = "******"
+
+// Config holds all server settings
+// Values are resolved in order: defaults, config file, environment variables, flags
+type Config struct {
+	Server       ServerConfig       `yaml:"server" toml:"server"`
+	Log          LogConfig          `yaml:"log" toml:"log"`
+	Session      SessionConfig      `yaml:"session" toml:"session"`
+	Connections  ConnectionsConfig  `yaml:"connections" toml:"connections"`
+	Auth         AuthConfig         `yaml:"auth" toml:"auth"`
+	Policies     PoliciesConfig     `yaml:"policies" toml:"policies"`
+	Confirmation ConfirmationConfig `yaml:"confirmation" toml:"confirmation"`
+	Audit        AuditConfig        `yaml:"audit" toml:"audit"`
+	Admin        AdminConfig        `yaml:"admin" toml:"admin"`
+	Calls        CallsConfig        `yaml:"calls" toml:"calls"`
+	RateLimit    RateLimitConfig    `yaml:"rate_limit" toml:"rate_limit"`
+	Metrics      MetricsConfig      `yaml:"metrics" toml:"metrics"`
+	Tracing      TracingConfig      `yaml:"tracing" toml:"tracing"`
+	Health       HealthConfig       `yaml:"health" toml:"health"`
+}
+
+// ServerConfig holds MCP transport settings
+type ServerConfig struct {
+	Transport         string        `yaml:"transport" toml:"transport"`
+	Addr              string        `yaml:"addr" toml:"addr"`
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval" toml:"heartbeat_interval"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	SlowCallThreshold time.Duration `yaml:"slow_call_threshold" toml:"slow_call_threshold"`
+	// ReadOnly registers only the tools that never modify Milvus
+	ReadOnly bool `yaml:"read_only" toml:"read_only"`
+}
+
+// LogConfig holds logging settings
+type LogConfig struct {
+	Level  string `yaml:"level" toml:"level"`
+	Format string `yaml:"format" toml:"format"`
+}
+
+// SessionConfig holds session manager and Ristretto cache settings
+type SessionConfig struct {
+	MaxSessions int           `yaml:"max_sessions" toml:"max_sessions"`
+	TTL         time.Duration `yaml:"ttl" toml:"ttl"`
+	// MaxConnections limits the Milvus clients shared by sessions with the same connection, 0 means no limit
+	MaxConnections int `yaml:"max_connections" toml:"max_connections"`
+	// HealthCheckInterval is how often Milvus connections are checked and reconnected, 0 disables it
+	HealthCheckInterval time.Duration `yaml:"health_check_interval" toml:"health_check_interval"`
+	NumCounters         int64         `yaml:"num_counters" toml:"num_counters"`
+	MaxCost             int64         `yaml:"max_cost" toml:"max_cost"`
+	BufferItems         int64         `yaml:"buffer_items" toml:"buffer_items"`
+	// Store is where sessions are kept, memory or redis to share them between replicas
+	Store string      `yaml:"store" toml:"store"`
+	Redis RedisConfig `yaml:"redis" toml:"redis"`
+}
+
+// RedisConfig holds the connection to a Redis compatible session store
+type RedisConfig struct {
+	Address   string `yaml:"address" toml:"address"`
+	Username  string `yaml:"username" toml:"username"`
+	Password  string `yaml:"password" toml:"password"`
+	DB        int    `yaml:"db" toml:"db"`
+	KeyPrefix string `yaml:"key_prefix" toml:"key_prefix"`
+	TLS       bool   `yaml:"tls" toml:"tls"`
+	// EncryptionKey is a base64 encoded 32 byte key sealing the credentials of custom
+	// connections, without it only profile sessions and connections without credentials are shared
+	EncryptionKey string `yaml:"encryption_key" toml:"encryption_key"`
+}
+
+// Key decodes the encryption key, returning nil if none is set
+func (c RedisConfig) Key() ([]byte, error) {
+	if c.EncryptionKey == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(c.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("session.redis.encryption_key must be base64 encoded: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("session.redis.encryption_key must decode to 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// ConnectionsConfig holds the server-side Milvus connection profiles
+type ConnectionsConfig struct {
+	// DefaultProfile is attached automatically to sessions that never call milvus_connector
+	DefaultProfile string `yaml:"default_profile" toml:"default_profile"`
+	// AllowCustom lets agents connect with their own address and credentials
+	AllowCustom bool                     `yaml:"allow_custom" toml:"allow_custom"`
+	Profiles    map[string]ProfileConfig `yaml:"profiles" toml:"profiles"`
+}
+
+// ProfileConfig describes a named Milvus connection
+// Credentials are given either as username/password, a raw token or an API key
+type ProfileConfig struct {
+	Address  string    `yaml:"address" toml:"address"`
+	Username string    `yaml:"username,omitempty" toml:"username"`
+	Password string    `yaml:"password,omitempty" toml:"password"`
+	Token    string    `yaml:"token,omitempty" toml:"token"`
+	APIKey   string    `yaml:"api_key,omitempty" toml:"api_key"`
+	DBName   string    `yaml:"db_name" toml:"db_name"`
+	TLS      TLSConfig `yaml:"tls,omitempty" toml:"tls"`
+}
+
+// TLSConfig controls how a profile secures its connection to Milvus
+// Setting a CA bundle, client certificate or server name implies Enable
+type TLSConfig struct {
+	Enable         bool   `yaml:"enable,omitempty" toml:"enable"`
+	CACertFile     string `yaml:"ca_cert_file,omitempty" toml:"ca_cert_file"`
+	ClientCertFile string `yaml:"client_cert_file,omitempty" toml:"client_cert_file"`
+	ClientKeyFile  string `yaml:"client_key_file,omitempty" toml:"client_key_file"`
+	ServerName     string `yaml:"server_name,omitempty" toml:"server_name"`
+}
+
+// AuthConfig holds inbound authentication settings for the HTTP transports
+// Clients authenticate with "Authorization: Bearer <token>" using either a
+// static token or a JWT signed by a key in the JWKS file
+type AuthConfig struct {
+	Enabled bool          `yaml:"enabled" toml:"enabled"`
+	Tokens  []TokenConfig `yaml:"tokens,omitempty" toml:"tokens"`
+	JWT     JWTConfig     `yaml:"jwt" toml:"jwt"`
+}
+
+// TokenConfig is a static bearer token issued to a named client
+type TokenConfig struct {
+	Name  string   `yaml:"name" toml:"name"`
+	Token string   `yaml:"token" toml:"token"`
+	Roles []string `yaml:"roles,omitempty" toml:"roles"`
+}
+
+// JWTConfig holds JWT validation settings
+type JWTConfig struct {
+	JWKSFile     string        `yaml:"jwks_file" toml:"jwks_file"`
+	Issuer       string        `yaml:"issuer" toml:"issuer"`
+	Audience     string        `yaml:"audience" toml:"audience"`
+	SubjectClaim string        `yaml:"subject_claim" toml:"subject_claim"`
+	RolesClaim   string        `yaml:"roles_claim" toml:"roles_claim"`
+	Leeway       time.Duration `yaml:"leeway" toml:"leeway"`
+}
+
+// ConfirmationConfig controls the two-phase confirmation of destructive tools
+type ConfirmationConfig struct {
+	Enabled bool          `yaml:"enabled" toml:"enabled"`
+	TTL     time.Duration `yaml:"ttl" toml:"ttl"`
+}
+
+// AdminConfig controls the operator HTTP endpoints
+type AdminConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// Addr is a separate listener, keep it reachable by operators only
+	Addr string `yaml:"addr" toml:"addr"`
+	// Role is required from callers when auth is enabled
+	Role string `yaml:"role" toml:"role"`
+}
+
+// CallsConfig controls timeouts, retries and circuit breaking of Milvus calls
+type CallsConfig struct {
+	// Timeout bounds every tool call, 0 disables it
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"`
+	// ToolTimeouts overrides Timeout for single tools, e.g. milvus_create_index
+	ToolTimeouts   map[string]time.Duration `yaml:"tool_timeouts,omitempty" toml:"tool_timeouts"`
+	Retry          RetryConfig              `yaml:"retry" toml:"retry"`
+	CircuitBreaker CircuitBreakerConfig     `yaml:"circuit_breaker" toml:"circuit_breaker"`
+}
+
+// RetryConfig controls the retries of Milvus RPCs failing with a retryable error
+type RetryConfig struct {
+	// MaxAttempts includes the first try, 1 disables retries
+	MaxAttempts    int           `yaml:"max_attempts" toml:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff" toml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" toml:"max_backoff"`
+}
+
+// CircuitBreakerConfig controls the circuit breaker of each Milvus address
+type CircuitBreakerConfig struct {
+	// FailureThreshold consecutive outages open the circuit, 0 disables the breaker
+	FailureThreshold int `yaml:"failure_threshold" toml:"failure_threshold"`
+	// OpenTimeout is how long calls fail fast before a probe call is let through
+	OpenTimeout time.Duration `yaml:"open_timeout" toml:"open_timeout"`
+}
+
+// RateLimitConfig limits the tool calls of each principal, or of each session without auth
+type RateLimitConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// CallsPerSecond and Burst form the token bucket of all tool calls, 0 disables it
+	CallsPerSecond float64 `yaml:"calls_per_second" toml:"calls_per_second"`
+	Burst          int     `yaml:"burst" toml:"burst"`
+	// ExpensiveTools additionally draw from a separate, smaller bucket
+	ExpensiveCallsPerSecond float64  `yaml:"expensive_calls_per_second" toml:"expensive_calls_per_second"`
+	ExpensiveBurst          int      `yaml:"expensive_burst" toml:"expensive_burst"`
+	ExpensiveTools          []string `yaml:"expensive_tools" toml:"expensive_tools"`
+	// MaxConcurrent limits the calls in flight at the same time, 0 disables it
+	MaxConcurrent int `yaml:"max_concurrent" toml:"max_concurrent"`
+}
+
+// MetricsConfig controls the Prometheus endpoint
+type MetricsConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// Addr is a separate, unauthenticated listener for the scraper
+	Addr string `yaml:"addr" toml:"addr"`
+	Path string `yaml:"path" toml:"path"`
+}
+
+// TracingConfig controls the OpenTelemetry spans of tool calls and Milvus RPCs
+type TracingConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// Exporter is otlp to send spans to a collector over gRPC, or file to append them as JSON
+	Exporter string `yaml:"exporter" toml:"exporter"`
+	Endpoint string `yaml:"endpoint" toml:"endpoint"`
+	Insecure bool   `yaml:"insecure" toml:"insecure"`
+	File     string `yaml:"file" toml:"file"`
+	// SampleRatio is the share of traces recorded when the client did not start one
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio"`
+	ServiceName string  `yaml:"service_name" toml:"service_name"`
+}
+
+// HealthConfig controls the /readyz probe served next to the MCP endpoints
+type HealthConfig struct {
+	// ProbeProfiles makes /readyz fail while a connection profile's Milvus server does not answer
+	ProbeProfiles bool          `yaml:"probe_profiles" toml:"probe_profiles"`
+	ProbeTimeout  time.Duration `yaml:"probe_timeout" toml:"probe_timeout"`
+	// ProbeInterval is how long a probe result is reused before /readyz probes again
+	ProbeInterval time.Duration `yaml:"probe_interval" toml:"probe_interval"`
+}
+
+// AuditConfig controls the audit trail of tool calls
+type AuditConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// File is the JSONL file events are appended to
+	File string `yaml:"file" toml:"file"`
+	// Log additionally writes events to the server log
+	Log bool `yaml:"log" toml:"log"`
+	// MaxArgumentLength truncates longer string arguments, e.g. inserted rows, and summarizes
+	// arrays and objects whose JSON is longer, 0 keeps them whole
+	MaxArgumentLength int `yaml:"max_argument_length" toml:"max_argument_length"`
+}
+
+// presetPolicies are the built-in policies that need no definition
+var presetPolicies = []string{"read-only", "read-write", "admin"}
+
+// PoliciesConfig maps principals, roles and connection profiles onto tool
+// authorization policies. A caller's policy is looked up by principal, then by
+// role, then by connection profile, falling back to Default; without any match
+// the caller is unrestricted.
+type PoliciesConfig struct {
+	Default     string                  `yaml:"default" toml:"default"`
+	Definitions map[string]PolicyConfig `yaml:"definitions,omitempty" toml:"definitions"`
+	Principals  map[string]string       `yaml:"principals,omitempty" toml:"principals"`
+	Roles       map[string]string       `yaml:"roles,omitempty" toml:"roles"`
+	Profiles    map[string]string       `yaml:"profiles,omitempty" toml:"profiles"`
+}
+
+// PolicyConfig defines a custom policy, all lists hold glob patterns
+// Preset optionally starts from the tools of a built-in policy
+type PolicyConfig struct {
+	Preset      string   `yaml:"preset,omitempty" toml:"preset"`
+	AllowTools  []string `yaml:"allow_tools,omitempty" toml:"allow_tools"`
+	DenyTools   []string `yaml:"deny_tools,omitempty" toml:"deny_tools"`
+	Databases   []string `yaml:"databases,omitempty" toml:"databases"`
+	Collections []string `yaml:"collections,omitempty" toml:"collections"`
+}
+
+// Default returns the configuration used when nothing is overridden
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Transport:         "sse",
+			Addr:              ":8080",
+			HeartbeatInterval: 30 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+			SlowCallThreshold: 10 * time.Second,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Session: SessionConfig{
+			MaxSessions:         1000,
+			TTL:                 1 * time.Hour,
+			MaxConnections:      100,
+			HealthCheckInterval: 30 * time.Second,
+			NumCounters:         1e7,
+			MaxCost:             1 << 30,
+			BufferItems:         64,
+			Store:               "memory",
+			Redis: RedisConfig{
+				KeyPrefix: "mcp-milvus:",
+			},
+		},
+		Connections: ConnectionsConfig{
+			AllowCustom: true,
+		},
+		Confirmation: ConfirmationConfig{
+			Enabled: true,
+			TTL:     2 * time.Minute,
+		},
+		Audit: AuditConfig{
+			MaxArgumentLength: 256,
+		},
+		Admin: AdminConfig{
+			Addr: "127.0.0.1:9090",
+			Role: "admin",
+		},
+		Metrics: MetricsConfig{
+			Addr: ":9091",
+			Path: "/metrics",
+		},
+		Health: HealthConfig{
+			ProbeTimeout:  5 * time.Second,
+			ProbeInterval: 10 * time.Second,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "otlp",
+			Endpoint:    "localhost:4317",
+			SampleRatio: 1,
+			ServiceName: "mcp-milvus",
+		},
+		Auth: AuthConfig{
+			JWT: JWTConfig{
+				SubjectClaim: "sub",
+				RolesClaim:   "roles",
+				Leeway:       30 * time.Second,
+			},
+		},
+		Calls: CallsConfig{
+			Timeout: 60 * time.Second,
+			// Index builds and loads wait for Milvus to finish
+			ToolTimeouts: map[string]time.Duration{
+				"milvus_create_index":    10 * time.Minute,
+				"milvus_load_collection": 10 * time.Minute,
+			},
+			Retry: RetryConfig{
+				MaxAttempts:    3,
+				InitialBackoff: 200 * time.Millisecond,
+				MaxBackoff:     2 * time.Second,
+			},
+			CircuitBreaker: CircuitBreakerConfig{
+				FailureThreshold: 5,
+				OpenTimeout:      30 * time.Second,
+			},
+		},
+		RateLimit: RateLimitConfig{
+			CallsPerSecond:          10,
+			Burst:                   20,
+			ExpensiveCallsPerSecond: 1,
+			ExpensiveBurst:          5,
+			ExpensiveTools:          []string{"milvus_vector_search", "milvus_hybrid_search", "milvus_insert_data", "milvus_upsert", "milvus_create_index"},
+			MaxConcurrent:           4,
+		},
+	}
+}
+
+// Options holds the command line flags understood by Load
+type Options struct {
+	ConfigFile  string
+	PrintConfig bool
+
+	flags *flag.FlagSet
+	cfg   Config
+}
+
+// RegisterFlags registers all config flags on fs
+// Flag values only override the config when they are explicitly set
+func RegisterFlags(fs *flag.FlagSet) *Options {
+	opts := &Options{flags: fs}
+	def := Default()
+
+	fs.StringVar(&opts.ConfigFile, "config", "", "Path to a YAML or TOML config file (env: "+EnvPrefix+"CONFIG)")
+	fs.BoolVar(&opts.PrintConfig, "print-config", false, "Print the effective configuration and exit")
+
+	fs.StringVar(&opts.cfg.Server.Transport, "transport", def.Server.Transport, "Transport to serve MCP over (stdio, sse, streamable-http)")
+	fs.StringVar(&opts.cfg.Server.Addr, "addr", def.Server.Addr, "Listen address for HTTP based transports")
+	fs.BoolVar(&opts.cfg.Server.ReadOnly, "read-only", def.Server.ReadOnly, "Only expose tools that never modify Milvus")
+	fs.DurationVar(&opts.cfg.Server.ShutdownTimeout, "shutdown-timeout", def.Server.ShutdownTimeout, "Maximum time to wait for a graceful shutdown")
+	fs.StringVar(&opts.cfg.Log.Level, "log-level", def.Log.Level, "Log level (debug, info, warn, error)")
+	fs.StringVar(&opts.cfg.Log.Format, "log-format", def.Log.Format, "Log format (json, text)")
+	fs.IntVar(&opts.cfg.Session.MaxSessions, "max-sessions", def.Session.MaxSessions, "Maximum number of concurrent MCP sessions")
+	fs.DurationVar(&opts.cfg.Session.TTL, "session-ttl", def.Session.TTL, "Idle time after which a session expires")
+
+	return opts
+}
+
+// Load resolves the effective configuration from defaults, the config file,
+// environment variables and explicitly set flags, then validates it
+func Load(opts *Options) (*Config, error) {
+	cfg := Default()
+
+	configFile := opts.ConfigFile
+	if configFile == "" {
+		configFile = os.Getenv(EnvPrefix + "CONFIG")
+	}
+	if configFile != "" {
+		if err := loadFile(configFile, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyEnv(cfg, os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if opts.flags != nil {
+		applyFlags(cfg, opts)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadFile decodes a YAML or TOML file, chosen by extension, on top of cfg
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// Secrets can be kept out of the file and referenced as ${ENV_VAR}
+	data = expandEnv(data)
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return fmt.Errorf("failed to parse YAML config %s: %w", path, err)
+		}
+	case ".toml":
+		if _, err := toml.Decode(string(data), cfg); err != nil {
+			return fmt.Errorf("failed to parse TOML config %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("unsupported config file format: %s (use .yaml, .yml or .toml)", path)
+	}
+	return nil
+}
+
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces ${VAR} references with the value of the environment variable
+func expandEnv(data []byte) []byte {
+	return envReference.ReplaceAllFunc(data, func(ref []byte) []byte {
+		name := envReference.FindSubmatch(ref)[1]
+		return []byte(os.Getenv(string(name)))
+	})
+}
+
+// envBinding maps an environment variable onto a config field
+type envBinding struct {
+	name  string
+	apply func(cfg *Config, value string) error
+}
+
+var envBindings = []envBinding{
+	{"TRANSPORT", func(c *Config, v string) error { c.Server.Transport = v; return nil }},
+	{"ADDR", func(c *Config, v string) error { c.Server.Addr = v; return nil }},
+	{"HEARTBEAT_INTERVAL", durationEnv(func(c *Config) *time.Duration { return &c.Server.HeartbeatInterval })},
+	{"SHUTDOWN_TIMEOUT", durationEnv(func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout })},
+	{"SLOW_CALL_THRESHOLD", durationEnv(func(c *Config) *time.Duration { return &c.Server.SlowCallThreshold })},
+	{"READ_ONLY", func(c *Config, v string) error {
+		readOnly, err := strconv.ParseBool(v)
+		c.Server.ReadOnly = readOnly
+		return err
+	}},
+	{"LOG_LEVEL", func(c *Config, v string) error { c.Log.Level = v; return nil }},
+	{"LOG_FORMAT", func(c *Config, v string) error { c.Log.Format = v; return nil }},
+	{"MAX_SESSIONS", intEnv(func(c *Config) *int { return &c.Session.MaxSessions })},
+	{"SESSION_TTL", durationEnv(func(c *Config) *time.Duration { return &c.Session.TTL })},
+	{"MAX_CONNECTIONS", intEnv(func(c *Config) *int { return &c.Session.MaxConnections })},
+	{"HEALTH_CHECK_INTERVAL", durationEnv(func(c *Config) *time.Duration { return &c.Session.HealthCheckInterval })},
+	{"CACHE_NUM_COUNTERS", int64Env(func(c *Config) *int64 { return &c.Session.NumCounters })},
+	{"CACHE_MAX_COST", int64Env(func(c *Config) *int64 { return &c.Session.MaxCost })},
+	{"CACHE_BUFFER_ITEMS", int64Env(func(c *Config) *int64 { return &c.Session.BufferItems })},
+	{"SESSION_STORE", func(c *Config, v string) error { c.Session.Store = v; return nil }},
+	{"REDIS_ADDRESS", func(c *Config, v string) error { c.Session.Redis.Address = v; return nil }},
+	{"REDIS_PASSWORD", func(c *Config, v string) error { c.Session.Redis.Password = v; return nil }},
+	{"REDIS_ENCRYPTION_KEY", func(c *Config, v string) error { c.Session.Redis.EncryptionKey = v; return nil }},
+	{"DEFAULT_PROFILE", func(c *Config, v string) error { c.Connections.DefaultProfile = v; return nil }},
+	{"ALLOW_CUSTOM_CONNECTIONS", func(c *Config, v string) error {
+		allow, err := strconv.ParseBool(v)
+		c.Connections.AllowCustom = allow
+		return err
+	}},
+	{"DEFAULT_POLICY", func(c *Config, v string) error { c.Policies.Default = v; return nil }},
+	{"CONFIRMATION_ENABLED", func(c *Config, v string) error {
+		enabled, err := strconv.ParseBool(v)
+		c.Confirmation.Enabled = enabled
+		return err
+	}},
+	{"CONFIRMATION_TTL", durationEnv(func(c *Config) *time.Duration { return &c.Confirmation.TTL })},
+	{"AUDIT_ENABLED", func(c *Config, v string) error {
+		enabled, err := strconv.ParseBool(v)
+		c.Audit.Enabled = enabled
+		return err
+	}},
+	{"AUDIT_FILE", func(c *Config, v string) error { c.Audit.File = v; return nil }},
+	{"ADMIN_ENABLED", func(c *Config, v string) error {
+		enabled, err := strconv.ParseBool(v)
+		c.Admin.Enabled = enabled
+		return err
+	}},
+	{"ADMIN_ADDR", func(c *Config, v string) error { c.Admin.Addr = v; return nil }},
+	{"RATE_LIMIT_ENABLED", func(c *Config, v string) error {
+		enabled, err := strconv.ParseBool(v)
+		c.RateLimit.Enabled = enabled
+		return err
+	}},
+	{"RATE_LIMIT_BURST", intEnv(func(c *Config) *int { return &c.RateLimit.Burst })},
+	{"RATE_LIMIT_MAX_CONCURRENT", intEnv(func(c *Config) *int { return &c.RateLimit.MaxConcurrent })},
+	{"METRICS_ENABLED", func(c *Config, v string) error {
+		enabled, err := strconv.ParseBool(v)
+		c.Metrics.Enabled = enabled
+		return err
+	}},
+	{"METRICS_ADDR", func(c *Config, v string) error { c.Metrics.Addr = v; return nil }},
+	{"TRACING_ENABLED", func(c *Config, v string) error {
+		enabled, err := strconv.ParseBool(v)
+		c.Tracing.Enabled = enabled
+		return err
+	}},
+	{"TRACING_EXPORTER", func(c *Config, v string) error { c.Tracing.Exporter = v; return nil }},
+	{"TRACING_ENDPOINT", func(c *Config, v string) error { c.Tracing.Endpoint = v; return nil }},
+	{"HEALTH_PROBE_PROFILES", func(c *Config, v string) error {
+		probe, err := strconv.ParseBool(v)
+		c.Health.ProbeProfiles = probe
+		return err
+	}},
+	{"HEALTH_PROBE_INTERVAL", durationEnv(func(c *Config) *time.Duration { return &c.Health.ProbeInterval })},
+	{"CALL_TIMEOUT", durationEnv(func(c *Config) *time.Duration { return &c.Calls.Timeout })},
+	{"RETRY_MAX_ATTEMPTS", intEnv(func(c *Config) *int { return &c.Calls.Retry.MaxAttempts })},
+	{"CIRCUIT_BREAKER_THRESHOLD", intEnv(func(c *Config) *int { return &c.Calls.CircuitBreaker.FailureThreshold })},
+	{"AUTH_ENABLED", func(c *Config, v string) error {
+		enabled, err := strconv.ParseBool(v)
+		c.Auth.Enabled = enabled
+		return err
+	}},
+	{"AUTH_JWKS_FILE", func(c *Config, v string) error { c.Auth.JWT.JWKSFile = v; return nil }},
+	{"AUTH_JWT_ISSUER", func(c *Config, v string) error { c.Auth.JWT.Issuer = v; return nil }},
+	{"AUTH_JWT_AUDIENCE", func(c *Config, v string) error { c.Auth.JWT.Audience = v; return nil }},
+}
+
+func durationEnv(field func(*Config) *time.Duration) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		*field(c) = d
+		return err
+	}
+}
+
+func intEnv(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		*field(c) = n
+		return err
+	}
+}
+
+func int64Env(field func(*Config) *int64) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.ParseInt(v, 10, 64)
+		*field(c) = n
+		return err
+	}
+}
+
+// applyEnv overrides cfg with MCP_MILVUS_* environment variables
+// LOG_LEVEL and PORT are still honored for backwards compatibility
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	if v, ok := lookup("LOG_LEVEL"); ok && v != "" {
+		cfg.Log.Level = v
+	}
+	if v, ok := lookup("PORT"); ok && v != "" {
+		cfg.Server.Addr = ":" + v
+	}
+
+	for _, binding := range envBindings {
+		name := EnvPrefix + binding.name
+		v, ok := lookup(name)
+		if !ok || v == "" {
+			continue
+		}
+		if err := binding.apply(cfg, v); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// applyFlags copies the explicitly set flags onto cfg
+func applyFlags(cfg *Config, opts *Options) {
+	opts.flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "transport":
+			cfg.Server.Transport = opts.cfg.Server.Transport
+		case "addr":
+			cfg.Server.Addr = opts.cfg.Server.Addr
+		case "read-only":
+			cfg.Server.ReadOnly = opts.cfg.Server.ReadOnly
+		case "shutdown-timeout":
+			cfg.Server.ShutdownTimeout = opts.cfg.Server.ShutdownTimeout
+		case "log-level":
+			cfg.Log.Level = opts.cfg.Log.Level
+		case "log-format":
+			cfg.Log.Format = opts.cfg.Log.Format
+		case "max-sessions":
+			cfg.Session.MaxSessions = opts.cfg.Session.MaxSessions
+		case "session-ttl":
+			cfg.Session.TTL = opts.cfg.Session.TTL
+		}
+	})
+}
+
+// Validate checks the configuration for invalid values
+func (c *Config) Validate() error {
+	switch c.Server.Transport {
+	case "stdio":
+	case "sse", "streamable-http":
+		if c.Server.Addr == "" {
+			return fmt.Errorf("server.addr is required for the %s transport", c.Server.Transport)
+		}
+	default:
+		return fmt.Errorf("server.transport must be one of stdio, sse, streamable-http, got %q", c.Server.Transport)
+	}
+	if c.Server.HeartbeatInterval < 0 {
+		return fmt.Errorf("server.heartbeat_interval must not be negative")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		return fmt.Errorf("server.shutdown_timeout must be positive")
+	}
+	if c.Server.SlowCallThreshold < 0 {
+		return fmt.Errorf("server.slow_call_threshold must not be negative")
+	}
+
+	if _, err := logrus.ParseLevel(c.Log.Level); err != nil {
+		return fmt.Errorf("log.level: %w", err)
+	}
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		return fmt.Errorf("log.format must be json or text, got %q", c.Log.Format)
+	}
+
+	if c.Session.MaxSessions <= 0 {
+		return fmt.Errorf("session.max_sessions must be positive")
+	}
+	if c.Session.TTL <= 0 {
+		return fmt.Errorf("session.ttl must be positive")
+	}
+	if c.Session.MaxConnections < 0 {
+		return fmt.Errorf("session.max_connections must not be negative")
+	}
+	if c.Session.HealthCheckInterval < 0 {
+		return fmt.Errorf("session.health_check_interval must not be negative")
+	}
+	switch c.Session.Store {
+	case "memory":
+	case "redis":
+		if c.Session.Redis.Address == "" {
+			return fmt.Errorf("session.redis.address is required with the redis session store")
+		}
+		if _, err := c.Session.Redis.Key(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("session.store must be memory or redis, got %q", c.Session.Store)
+	}
+	if c.Session.NumCounters <= 0 || c.Session.MaxCost <= 0 || c.Session.BufferItems <= 0 {
+		return fmt.Errorf("session.num_counters, session.max_cost and session.buffer_items must be positive")
+	}
+
+	for name, profile := range c.Connections.Profiles {
+		if name == "" {
+			return fmt.Errorf("connections.profiles must not contain an empty name")
+		}
+		if profile.Address == "" {
+			return fmt.Errorf("connections.profiles.%s.address is required", name)
+		}
+		if profile.Token != "" && profile.Username != "" {
+			return fmt.Errorf("connections.profiles.%s: set either token or username/password, not both", name)
+		}
+		if profile.APIKey != "" && (profile.Token != "" || profile.Username != "") {
+			return fmt.Errorf("connections.profiles.%s: set either api_key or token/username, not both", name)
+		}
+		if (profile.TLS.ClientCertFile == "") != (profile.TLS.ClientKeyFile == "") {
+			return fmt.Errorf("connections.profiles.%s.tls: client_cert_file and client_key_file must be set together", name)
+		}
+	}
+	if c.Connections.DefaultProfile != "" {
+		if _, ok := c.Connections.Profiles[c.Connections.DefaultProfile]; !ok {
+			return fmt.Errorf("connections.default_profile %q is not a configured profile", c.Connections.DefaultProfile)
+		}
+	}
+	if !c.Connections.AllowCustom && len(c.Connections.Profiles) == 0 {
+		return fmt.Errorf("connections.allow_custom is false but no connection profiles are configured")
+	}
+
+	if c.Confirmation.Enabled && c.Confirmation.TTL <= 0 {
+		return fmt.Errorf("confirmation.ttl must be positive")
+	}
+
+	if c.Audit.Enabled && c.Audit.File == "" && !c.Audit.Log {
+		return fmt.Errorf("audit is enabled but neither audit.file nor audit.log is set")
+	}
+	if c.Audit.MaxArgumentLength < 0 {
+		return fmt.Errorf("audit.max_argument_length must not be negative")
+	}
+
+	if c.Admin.Enabled {
+		if c.Admin.Addr == "" {
+			return fmt.Errorf("admin.addr is required when the admin endpoints are enabled")
+		}
+		if c.Admin.Addr == c.Server.Addr && c.Server.Transport != "stdio" {
+			return fmt.Errorf("admin.addr must differ from server.addr")
+		}
+		if c.Auth.Enabled && c.Admin.Role == "" {
+			return fmt.Errorf("admin.role is required when auth is enabled")
+		}
+	}
+
+	if c.Metrics.Enabled {
+		if c.Metrics.Addr == "" || !strings.HasPrefix(c.Metrics.Path, "/") {
+			return fmt.Errorf("metrics.addr and a metrics.path starting with / are required when metrics are enabled")
+		}
+		if c.Metrics.Addr == c.Server.Addr && c.Server.Transport != "stdio" {
+			return fmt.Errorf("metrics.addr must differ from server.addr")
+		}
+		if c.Admin.Enabled && c.Metrics.Addr == c.Admin.Addr {
+			return fmt.Errorf("metrics.addr must differ from admin.addr")
+		}
+	}
+	if c.Health.ProbeProfiles && c.Health.ProbeTimeout <= 0 {
+		return fmt.Errorf("health.probe_timeout must be positive when probing profiles")
+	}
+	if c.Health.ProbeInterval < 0 {
+		return fmt.Errorf("health.probe_interval must not be negative")
+	}
+	if c.Tracing.Enabled {
+		switch c.Tracing.Exporter {
+		case "otlp":
+			if c.Tracing.Endpoint == "" {
+				return fmt.Errorf("tracing.endpoint is required with the otlp exporter")
+			}
+		case "file":
+			if c.Tracing.File == "" {
+				return fmt.Errorf("tracing.file is required with the file exporter")
+			}
+		default:
+			return fmt.Errorf("tracing.exporter must be otlp or file, got %s", c.Tracing.Exporter)
+		}
+		if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+			return fmt.Errorf("tracing.sample_ratio must be between 0 and 1")
+		}
+	}
+	if err := c.Calls.validate(); err != nil {
+		return err
+	}
+	if c.RateLimit.Enabled {
+		if c.RateLimit.CallsPerSecond < 0 || c.RateLimit.ExpensiveCallsPerSecond < 0 || c.RateLimit.MaxConcurrent < 0 {
+			return fmt.Errorf("rate_limit.calls_per_second, rate_limit.expensive_calls_per_second and rate_limit.max_concurrent must not be negative")
+		}
+		if (c.RateLimit.CallsPerSecond > 0 && c.RateLimit.Burst <= 0) || (c.RateLimit.ExpensiveCallsPerSecond > 0 && c.RateLimit.ExpensiveBurst <= 0) {
+			return fmt.Errorf("rate_limit.burst and rate_limit.expensive_burst must be positive with a call rate")
+		}
+	}
+	if err := c.Auth.validate(c.Server.Transport); err != nil {
+		return err
+	}
+	return c.Policies.validate()
+}
+
+func (c *CallsConfig) validate() error {
+	if c.Timeout < 0 {
+		return fmt.Errorf("calls.timeout must not be negative")
+	}
+	for tool, timeout := range c.ToolTimeouts {
+		if timeout < 0 {
+			return fmt.Errorf("calls.tool_timeouts.%s must not be negative", tool)
+		}
+	}
+	if c.Retry.MaxAttempts < 1 {
+		return fmt.Errorf("calls.retry.max_attempts must be at least 1")
+	}
+	if c.Retry.InitialBackoff < 0 || c.Retry.MaxBackoff < c.Retry.InitialBackoff {
+		return fmt.Errorf("calls.retry.initial_backoff must not be negative or exceed calls.retry.max_backoff")
+	}
+	if c.CircuitBreaker.FailureThreshold < 0 {
+		return fmt.Errorf("calls.circuit_breaker.failure_threshold must not be negative")
+	}
+	if c.CircuitBreaker.FailureThreshold > 0 && c.CircuitBreaker.OpenTimeout <= 0 {
+		return fmt.Errorf("calls.circuit_breaker.open_timeout must be positive")
+	}
+	return nil
+}
+
+func (p *PoliciesConfig) validate() error {
+	known := func(name string) bool {
+		_, ok := p.Definitions[name]
+		return ok || isPresetPolicy(name)
+	}
+
+	for name, def := range p.Definitions {
+		if isPresetPolicy(name) {
+			return fmt.Errorf("policies.definitions.%s: name is reserved for a built-in policy", name)
+		}
+		if def.Preset != "" && !isPresetPolicy(def.Preset) {
+			return fmt.Errorf("policies.definitions.%s.preset must be one of %s", name, strings.Join(presetPolicies, ", "))
+		}
+	}
+	for kind, bindings := range map[string]map[string]string{"principals": p.Principals, "roles": p.Roles, "profiles": p.Profiles} {
+		for subject, name := range bindings {
+			if !known(name) {
+				return fmt.Errorf("policies.%s.%s references unknown policy %q", kind, subject, name)
+			}
+		}
+	}
+	if p.Default != "" && !known(p.Default) {
+		return fmt.Errorf("policies.default references unknown policy %q", p.Default)
+	}
+	return nil
+}
+
+func isPresetPolicy(name string) bool {
+	for _, preset := range presetPolicies {
+		if name == preset {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *AuthConfig) validate(transport string) error {
+	if !a.Enabled {
+		return nil
+	}
+	if transport == "stdio" {
+		return fmt.Errorf("auth is only supported on the sse and streamable-http transports")
+	}
+	if len(a.Tokens) == 0 && a.JWT.JWKSFile == "" {
+		return fmt.Errorf("auth is enabled but neither auth.tokens nor auth.jwt.jwks_file is configured")
+	}
+
+	names := make(map[string]bool, len(a.Tokens))
+	for i, token := range a.Tokens {
+		if token.Name == "" || token.Token == "" {
+			return fmt.Errorf("auth.tokens[%d] needs both a name and a token", i)
+		}
+		if names[token.Name] {
+			return fmt.Errorf("auth.tokens: duplicate name %q", token.Name)
+		}
+		names[token.Name] = true
+	}
+
+	if a.JWT.JWKSFile != "" && a.JWT.SubjectClaim == "" {
+		return fmt.Errorf("auth.jwt.subject_claim must not be empty")
+	}
+	if a.JWT.Leeway < 0 {
+		return fmt.Errorf("auth.jwt.leeway must not be negative")
+	}
+	return nil
+}
+
+// Credentials returns the profile credentials in the username:password token format
+func (p ProfileConfig) Credentials() string {
+	if p.Username != "" {
+		return p.Username + ":" + p.Password
+	}
+	return p.Token
+}
+
+// String renders the configuration as YAML with secrets redacted
+func (c *Config) String() string {
+	redacted := *c
+	redacted.Connections.Profiles = make(map[string]ProfileConfig, len(c.Connections.Profiles))
+	for name, profile := range c.Connections.Profiles {
+		if profile.Password != "" {
+			profile.Password = redactedSecret
+		}
+		if profile.Token != "" {
+			profile.Token = redactedSecret
+		}
+		if profile.APIKey != "" {
+			profile.APIKey = redactedSecret
+		}
+		redacted.Connections.Profiles[name] = profile
+	}
+	if c.Session.Redis.Password != "" {
+		redacted.Session.Redis.Password = redactedSecret
+	}
+	if c.Session.Redis.EncryptionKey != "" {
+		redacted.Session.Redis.EncryptionKey = redactedSecret
+	}
+	redacted.Auth.Tokens = make([]TokenConfig, len(c.Auth.Tokens))
+	for i, token := range c.Auth.Tokens {
+		token.Token = redactedSecret
+		redacted.Auth.Tokens[i] = token
+	}
+
+	out, err := yaml.Marshal(&redacted)
+	if err != nil {
+		return fmt.Sprintf("failed to render config: %v", err)
+	}
+	return string(out)
+}
@@ -0,0 +1,362 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestDefaultIsValid(t *testing.T) {
+	cfg := Default()
+	assert.NoError(t, cfg.Validate())
+	assert.Equal(t, 1000, cfg.Session.MaxSessions)
+	assert.Equal(t, 100, cfg.Session.MaxConnections)
+	assert.Equal(t, 30*time.Second, cfg.Session.HealthCheckInterval)
+	assert.Equal(t, time.Hour, cfg.Session.TTL)
+}
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "yaml",
+			file: "config.yaml",
+			content: `
+server:
+  transport: streamable-http
+  addr: ":9090"
+session:
+  max_sessions: 500
+  ttl: 30m
+calls:
+  tool_timeouts:
+    milvus_vector_search: 5s
+`,
+		},
+		{
+			name: "toml",
+			file: "config.toml",
+			content: `
+[server]
+transport = "streamable-http"
+addr = ":9090"
+
+[session]
+max_sessions = 500
+ttl = "30m"
+
+[calls.tool_timeouts]
+milvus_vector_search = "5s"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(&Options{ConfigFile: writeFile(t, tt.file, tt.content)})
+			require.NoError(t, err)
+			assert.Equal(t, "streamable-http", cfg.Server.Transport)
+			assert.Equal(t, ":9090", cfg.Server.Addr)
+			assert.Equal(t, 500, cfg.Session.MaxSessions)
+			assert.Equal(t, 30*time.Minute, cfg.Session.TTL)
+			assert.Equal(t, 5*time.Second, cfg.Calls.ToolTimeouts["milvus_vector_search"])
+			// Unset values keep their defaults
+			assert.Equal(t, "info", cfg.Log.Level)
+			assert.Equal(t, int64(64), cfg.Session.BufferItems)
+		})
+	}
+}
+
+func TestLoadUnsupportedFile(t *testing.T) {
+	_, err := Load(&Options{ConfigFile: writeFile(t, "config.json", "{}")})
+	assert.Error(t, err)
+}
+
+func TestPrecedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  addr: ":7000"
+log:
+  level: warn
+session:
+  max_sessions: 10
+`)
+	t.Setenv(EnvPrefix+"LOG_LEVEL", "debug")
+	t.Setenv(EnvPrefix+"MAX_SESSIONS", "20")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	opts := RegisterFlags(fs)
+	require.NoError(t, fs.Parse([]string{"--config", path, "--max-sessions", "30"}))
+
+	cfg, err := Load(opts)
+	require.NoError(t, err)
+	assert.Equal(t, ":7000", cfg.Server.Addr, "file overrides default")
+	assert.Equal(t, "debug", cfg.Log.Level, "env overrides file")
+	assert.Equal(t, 30, cfg.Session.MaxSessions, "flag overrides env")
+	assert.Equal(t, "sse", cfg.Server.Transport, "unset flag does not override")
+}
+
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"PORT":                         "9000",
+		EnvPrefix + "SESSION_TTL":      "15m",
+		EnvPrefix + "CACHE_MAX_COST":   "1024",
+		EnvPrefix + "SHUTDOWN_TIMEOUT": "5s",
+		EnvPrefix + "READ_ONLY":        "true",
+	}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	cfg := Default()
+	require.NoError(t, applyEnv(cfg, lookup))
+	assert.Equal(t, ":9000", cfg.Server.Addr)
+	assert.Equal(t, 15*time.Minute, cfg.Session.TTL)
+	assert.Equal(t, int64(1024), cfg.Session.MaxCost)
+	assert.Equal(t, 5*time.Second, cfg.Server.ShutdownTimeout)
+	assert.True(t, cfg.Server.ReadOnly)
+
+	env[EnvPrefix+"MAX_SESSIONS"] = "many"
+	assert.Error(t, applyEnv(Default(), lookup))
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*Config)
+	}{
+		{"unknown transport", func(c *Config) { c.Server.Transport = "grpc" }},
+		{"missing addr", func(c *Config) { c.Server.Addr = "" }},
+		{"bad log level", func(c *Config) { c.Log.Level = "loud" }},
+		{"bad log format", func(c *Config) { c.Log.Format = "xml" }},
+		{"zero max sessions", func(c *Config) { c.Session.MaxSessions = 0 }},
+		{"negative max connections", func(c *Config) { c.Session.MaxConnections = -1 }},
+		{"negative health check interval", func(c *Config) { c.Session.HealthCheckInterval = -time.Second }},
+		{"zero ttl", func(c *Config) { c.Session.TTL = 0 }},
+		{"unknown session store", func(c *Config) { c.Session.Store = "etcd" }},
+		{"redis store without address", func(c *Config) { c.Session.Store = "redis" }},
+		{"short redis encryption key", func(c *Config) {
+			c.Session.Store = "redis"
+			c.Session.Redis.Address = "redis:6379"
+			c.Session.Redis.EncryptionKey = "c2hvcnQ="
+		}},
+		{"admin on the server addr", func(c *Config) { c.Admin.Enabled = true; c.Admin.Addr = c.Server.Addr }},
+		{"zero cache cost", func(c *Config) { c.Session.MaxCost = 0 }},
+		{"zero shutdown timeout", func(c *Config) { c.Server.ShutdownTimeout = 0 }},
+		{"negative tool timeout", func(c *Config) { c.Calls.ToolTimeouts["milvus_query"] = -time.Second }},
+		{"rate limit without burst", func(c *Config) { c.RateLimit.Enabled = true; c.RateLimit.Burst = 0 }},
+		{"negative max concurrent", func(c *Config) { c.RateLimit.Enabled = true; c.RateLimit.MaxConcurrent = -1 }},
+		{"metrics on the server addr", func(c *Config) { c.Metrics.Enabled = true; c.Metrics.Addr = c.Server.Addr }},
+		{"metrics path without slash", func(c *Config) { c.Metrics.Enabled = true; c.Metrics.Path = "metrics" }},
+		{"profile probe without timeout", func(c *Config) { c.Health.ProbeProfiles = true; c.Health.ProbeTimeout = 0 }},
+		{"negative probe interval", func(c *Config) { c.Health.ProbeInterval = -time.Second }},
+		{"tracing with unknown exporter", func(c *Config) { c.Tracing.Enabled = true; c.Tracing.Exporter = "jaeger" }},
+		{"tracing file exporter without file", func(c *Config) { c.Tracing.Enabled = true; c.Tracing.Exporter = "file" }},
+		{"tracing sample ratio above one", func(c *Config) { c.Tracing.Enabled = true; c.Tracing.SampleRatio = 2 }},
+		{"zero retry attempts", func(c *Config) { c.Calls.Retry.MaxAttempts = 0 }},
+		{"backoff above max", func(c *Config) { c.Calls.Retry.InitialBackoff = time.Minute }},
+		{"breaker without open timeout", func(c *Config) { c.Calls.CircuitBreaker.OpenTimeout = 0 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.mutate(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+
+	stdio := Default()
+	stdio.Server.Transport = "stdio"
+	stdio.Server.Addr = ""
+	assert.NoError(t, stdio.Validate(), "stdio does not need a listen address")
+}
+
+func TestString(t *testing.T) {
+	out := Default().String()
+	assert.Contains(t, out, "transport: sse")
+	assert.Contains(t, out, "ttl: 1h0m0s")
+
+	cfg := Default()
+	cfg.Session.Redis.Password = "redis-secret"
+	cfg.Session.Redis.EncryptionKey = "redis-encryption-key"
+	assert.NotContains(t, cfg.String(), "redis-secret")
+	assert.NotContains(t, cfg.String(), "redis-encryption-key")
+}
+
+func TestExampleConfigMatchesDefaults(t *testing.T) {
+	cfg, err := Load(&Options{ConfigFile: filepath.Join("..", "..", "config.example.yaml")})
+	require.NoError(t, err)
+	assert.Equal(t, Default(), cfg)
+}
+
+func TestConnectionProfiles(t *testing.T) {
+	t.Setenv("TEST_MILVUS_PASSWORD", "s3cret")
+	path := writeFile(t, "config.yaml", `
+connections:
+  default_profile: prod
+  allow_custom: false
+  profiles:
+    prod:
+      address: https://milvus.example.com:19530
+      username: analyst
+      password: ${TEST_MILVUS_PASSWORD}
+      db_name: analytics
+    cloud:
+      address: https://in01.zillizcloud.com
+      token: user:key
+    zilliz:
+      address: https://in03.zillizcloud.com
+      api_key: zilliz-key
+    internal:
+      address: milvus.internal:19530
+      tls:
+        enable: true
+        ca_cert_file: /etc/ca.pem
+        client_cert_file: /etc/client.pem
+        client_key_file: /etc/client-key.pem
+        server_name: milvus.internal
+`)
+
+	cfg, err := Load(&Options{ConfigFile: path})
+	require.NoError(t, err)
+	assert.Equal(t, "prod", cfg.Connections.DefaultProfile)
+	assert.False(t, cfg.Connections.AllowCustom)
+	assert.Equal(t, "analyst:s3cret", cfg.Connections.Profiles["prod"].Credentials())
+	assert.Equal(t, "user:key", cfg.Connections.Profiles["cloud"].Credentials())
+	assert.Equal(t, "zilliz-key", cfg.Connections.Profiles["zilliz"].APIKey)
+	assert.Equal(t, TLSConfig{
+		Enable:         true,
+		CACertFile:     "/etc/ca.pem",
+		ClientCertFile: "/etc/client.pem",
+		ClientKeyFile:  "/etc/client-key.pem",
+		ServerName:     "milvus.internal",
+	}, cfg.Connections.Profiles["internal"].TLS)
+
+	out := cfg.String()
+	assert.NotContains(t, out, "s3cret")
+	assert.NotContains(t, out, "user:key")
+	assert.NotContains(t, out, "zilliz-key")
+	assert.Equal(t, "s3cret", cfg.Connections.Profiles["prod"].Password, "redaction must not modify the config")
+}
+
+func TestValidateConnections(t *testing.T) {
+	tests := []struct {
+		name        string
+		connections ConnectionsConfig
+	}{
+		{"missing address", ConnectionsConfig{AllowCustom: true, Profiles: map[string]ProfileConfig{"a": {}}}},
+		{"unknown default", ConnectionsConfig{AllowCustom: true, DefaultProfile: "b", Profiles: map[string]ProfileConfig{"a": {Address: "x"}}}},
+		{"token and username", ConnectionsConfig{AllowCustom: true, Profiles: map[string]ProfileConfig{"a": {Address: "x", Token: "t", Username: "u"}}}},
+		{"api key and token", ConnectionsConfig{AllowCustom: true, Profiles: map[string]ProfileConfig{"a": {Address: "x", Token: "t", APIKey: "k"}}}},
+		{"client cert without key", ConnectionsConfig{AllowCustom: true, Profiles: map[string]ProfileConfig{"a": {Address: "x", TLS: TLSConfig{ClientCertFile: "c"}}}}},
+		{"no way to connect", ConnectionsConfig{AllowCustom: false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Connections = tt.connections
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
+
+func TestAuth(t *testing.T) {
+	t.Setenv("TEST_CI_TOKEN", "ci-secret")
+	path := writeFile(t, "config.yaml", `
+auth:
+  enabled: true
+  tokens:
+    - name: ci
+      token: ${TEST_CI_TOKEN}
+      roles: [reader]
+  jwt:
+    jwks_file: /etc/mcp-milvus/jwks.json
+    issuer: https://issuer.example.com
+`)
+
+	cfg, err := Load(&Options{ConfigFile: path})
+	require.NoError(t, err)
+	assert.True(t, cfg.Auth.Enabled)
+	assert.Equal(t, []TokenConfig{{Name: "ci", Token: "ci-secret", Roles: []string{"reader"}}}, cfg.Auth.Tokens)
+	assert.Equal(t, "sub", cfg.Auth.JWT.SubjectClaim, "unset values keep their defaults")
+	assert.NotContains(t, cfg.String(), "ci-secret")
+	assert.Equal(t, "ci-secret", cfg.Auth.Tokens[0].Token, "redaction must not modify the config")
+}
+
+func TestValidateAuth(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*Config)
+	}{
+		{"no methods", func(c *Config) {}},
+		{"stdio", func(c *Config) {
+			c.Server.Transport = "stdio"
+			c.Auth.JWT.JWKSFile = "jwks.json"
+		}},
+		{"token without name", func(c *Config) { c.Auth.Tokens = []TokenConfig{{Token: "t"}} }},
+		{"duplicate token name", func(c *Config) {
+			c.Auth.Tokens = []TokenConfig{{Name: "a", Token: "t1"}, {Name: "a", Token: "t2"}}
+		}},
+		{"empty subject claim", func(c *Config) {
+			c.Auth.JWT.JWKSFile = "jwks.json"
+			c.Auth.JWT.SubjectClaim = ""
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Auth.Enabled = true
+			tt.mutate(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
+
+func TestValidatePolicies(t *testing.T) {
+	valid := PoliciesConfig{
+		Default:     "read-only",
+		Definitions: map[string]PolicyConfig{"analyst": {Preset: "read-only", Databases: []string{"analytics"}}},
+		Principals:  map[string]string{"alice": "admin"},
+		Roles:       map[string]string{"reader": "analyst"},
+	}
+	cfg := Default()
+	cfg.Policies = valid
+	assert.NoError(t, cfg.Validate())
+
+	tests := []struct {
+		name     string
+		policies PoliciesConfig
+	}{
+		{"reserved name", PoliciesConfig{Definitions: map[string]PolicyConfig{"admin": {}}}},
+		{"unknown preset", PoliciesConfig{Definitions: map[string]PolicyConfig{"a": {Preset: "superuser"}}}},
+		{"unknown principal policy", PoliciesConfig{Principals: map[string]string{"alice": "missing"}}},
+		{"unknown profile policy", PoliciesConfig{Profiles: map[string]string{"prod": "missing"}}},
+		{"unknown default", PoliciesConfig{Default: "missing"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Policies = tt.policies
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
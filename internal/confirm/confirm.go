@@ -0,0 +1,244 @@
+package confirm
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tailabs/mcp-milvus/internal/session"
+	"github.com/tailabs/mcp-milvus/internal/session/store"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// TokenArgument carries the confirmation token on the second, executing call
+	TokenArgument = "confirmation_token"
+	// DryRunArgument asks for the preview only, without issuing a token
+	DryRunArgument = "dry_run"
+)
+
+// Previewer is implemented by destructive tools. Registering such a tool makes it
+// two-phase: the first call returns the preview and a short-lived token, only a
+// second call with the same arguments and the token executes it.
+type Previewer interface {
+	// Preview describes what the call would destroy, e.g. the number of matching entities
+	Preview(ctx context.Context, request mcp.CallToolRequest) (string, error)
+}
+
+// Manager issues and redeems single-use confirmation tokens. A token is bound to
+// the session, the tool, the Milvus connection and database and the exact arguments
+// it was issued for. Tokens are kept in the session store, so with a shared store
+// the confirming call may reach another replica than the preview.
+type Manager struct {
+	store   store.Store
+	ttl     time.Duration
+	enabled bool
+}
+
+// Target is the Milvus connection and database a call runs against
+type Target struct {
+	Address  string `json:"address"`
+	Profile  string `json:"profile,omitempty"`
+	Database string `json:"database"`
+}
+
+type pendingCall struct {
+	SessionID string `json:"session_id"`
+	Tool      string `json:"tool"`
+	Digest    string `json:"digest"`
+}
+
+// tokenPrefix namespaces the tokens in the session store
+const tokenPrefix = "confirm/"
+
+// storeTimeout bounds every round trip to the store
+const storeTimeout = 5 * time.Second
+
+// NewManager creates a confirmation manager whose tokens are valid for ttl
+// st keeps the tokens, nil keeps them in this process
+func NewManager(enabled bool, ttl time.Duration, st store.Store) *Manager {
+	if st == nil {
+		st = store.NewMemory()
+	}
+	return &Manager{
+		store:   st,
+		ttl:     ttl,
+		enabled: enabled,
+	}
+}
+
+var manager = NewManager(true, 2*time.Minute, nil)
+
+// Configure replaces the global confirmation manager
+// It must be called before the tools are registered to take effect
+func Configure(enabled bool, ttl time.Duration, st store.Store) {
+	manager = NewManager(enabled, ttl, st)
+}
+
+// GetManager returns the global confirmation manager
+func GetManager() *Manager {
+	return manager
+}
+
+// Enabled reports whether destructive tools require confirmation
+func (m *Manager) Enabled() bool {
+	return m.enabled
+}
+
+// Issue creates a token for the call
+func (m *Manager) Issue(sessionID, tool string, target Target, args map[string]any) (string, error) {
+	digest, err := callDigest(target, args)
+	if err != nil {
+		return "", err
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	data, err := json.Marshal(pendingCall{SessionID: sessionID, Tool: tool, Digest: digest})
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := m.store.Set(ctx, tokenPrefix+token, data, m.ttl); err != nil {
+		return "", fmt.Errorf("failed to store confirmation token: %w", err)
+	}
+	return token, nil
+}
+
+// Redeem consumes the token if it was issued for exactly this call
+func (m *Manager) Redeem(token, sessionID, tool string, target Target, args map[string]any) error {
+	digest, err := callDigest(target, args)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	invalid := fmt.Errorf("invalid or expired confirmation token, call %s without %s to get a new preview", tool, TokenArgument)
+
+	data, err := m.store.Get(ctx, tokenPrefix+token)
+	if errors.Is(err, store.ErrNotFound) {
+		return invalid
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load confirmation token: %w", err)
+	}
+	var call pendingCall
+	if err := json.Unmarshal(data, &call); err != nil {
+		return invalid
+	}
+	if call.SessionID != sessionID || call.Tool != tool || call.Digest != digest {
+		return fmt.Errorf("confirmation token was issued for a different call, the arguments, connection and database must not change between preview and confirmation")
+	}
+
+	// Of concurrent confirmations only the one taking the token executes
+	if _, err := m.store.Take(ctx, tokenPrefix+token); errors.Is(err, store.ErrNotFound) {
+		return invalid
+	} else if err != nil {
+		return fmt.Errorf("failed to redeem confirmation token: %w", err)
+	}
+	return nil
+}
+
+// callDigest hashes the call target and arguments, leaving out the protocol arguments
+func callDigest(target Target, args map[string]any) (string, error) {
+	filtered := make(map[string]any, len(args))
+	for k, v := range args {
+		if k != TokenArgument && k != DryRunArgument {
+			filtered[k] = v
+		}
+	}
+	// Map keys are marshalled in sorted order, so equal arguments give equal digests
+	data, err := json.Marshal(struct {
+		Target    Target         `json:"target"`
+		Arguments map[string]any `json:"arguments"`
+	}{target, filtered})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode arguments: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// callTarget returns the connection and database the session's call runs against
+func callTarget(ctx context.Context, sessionID string, request mcp.CallToolRequest) (Target, error) {
+	state, err := session.CallState(ctx, sessionID)
+	if err != nil {
+		return Target{}, err
+	}
+	target := Target{
+		Address:  state.ConnConfig.Address,
+		Profile:  state.ConnConfig.Profile,
+		Database: request.GetString("db_name", state.ConnConfig.DBName),
+	}
+	if target.Database == "" {
+		target.Database = "default"
+	}
+	return target, nil
+}
+
+// WithConfirmation adds the confirmation arguments to the tool schema
+func (m *Manager) WithConfirmation(tool mcp.Tool) mcp.Tool {
+	tool.Description += fmt.Sprintf(" This operation is destructive: the first call only returns a preview and a %s valid for %s,"+
+		" call again with the same arguments and the token to execute it.", TokenArgument, m.ttl)
+	mcp.WithString(TokenArgument,
+		mcp.Description("Token from the preview call, confirms the operation."),
+	)(&tool)
+	mcp.WithBoolean(DryRunArgument,
+		mcp.Description("Only return the preview, without issuing a confirmation token."),
+	)(&tool)
+	return tool
+}
+
+// Wrap makes the handler two-phase using the previewer
+func (m *Manager) Wrap(previewer Previewer, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		sessionClient := server.ClientSessionFromContext(ctx)
+		if sessionClient == nil {
+			return mcp.NewToolResultError("must provide an available session id"), nil
+		}
+		sessionID := sessionClient.SessionID()
+		tool := request.Params.Name
+		args := request.GetArguments()
+		target, err := callTarget(ctx, sessionID, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		if token := request.GetString(TokenArgument, ""); token != "" {
+			if err := m.Redeem(token, sessionID, tool, target, args); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return next(ctx, request)
+		}
+
+		preview, err := previewer.Preview(ctx, request)
+		if err != nil {
+			return mcp.NewToolResultError("Failed to preview operation: " + err.Error()), nil
+		}
+
+		if request.GetBool(DryRunArgument, false) {
+			return mcp.NewToolResultText(fmt.Sprintf("Dry run, nothing was changed.\n\n%s", preview)), nil
+		}
+
+		token, err := m.Issue(sessionID, tool, target, args)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Preview, nothing was changed yet.\n\n%s\n\n"+
+			"To execute, call %s again with the same arguments and %s: %q (valid for %s).",
+			preview, tool, TokenArgument, token, m.ttl)), nil
+	}
+}
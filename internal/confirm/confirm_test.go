@@ -0,0 +1,161 @@
+package confirm
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/tailabs/mcp-milvus/internal/session"
+	"github.com/tailabs/mcp-milvus/internal/session/store"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSession struct {
+	id string
+}
+
+func (s *fakeSession) Initialize()                                         {}
+func (s *fakeSession) Initialized() bool                                   { return true }
+func (s *fakeSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s *fakeSession) SessionID() string                                   { return s.id }
+
+type fakePreviewer struct{}
+
+func (fakePreviewer) Preview(ctx context.Context, request mcp.CallToolRequest) (string, error) {
+	return "would delete 42 entities", nil
+}
+
+func callRequest(tool string, args map[string]any) mcp.CallToolRequest {
+	var req mcp.CallToolRequest
+	req.Params.Name = tool
+	req.Params.Arguments = args
+	return req
+}
+
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	require.Len(t, result.Content, 1)
+	text, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	return text.Text
+}
+
+var target = Target{Address: "localhost:19530", Database: "default"}
+
+func TestIssueAndRedeem(t *testing.T) {
+	m := NewManager(true, time.Minute, nil)
+	args := map[string]any{"collection_name": "docs", "filter_expr": "id > 0"}
+
+	token, err := m.Issue("s1", "milvus_delete_entities", target, args)
+	require.NoError(t, err)
+
+	assert.Error(t, m.Redeem(token, "s2", "milvus_delete_entities", target, args), "other session")
+	assert.Error(t, m.Redeem(token, "s1", "milvus_drop_collection", target, args), "other tool")
+	assert.Error(t, m.Redeem(token, "s1", "milvus_delete_entities", target, map[string]any{"collection_name": "docs", "filter_expr": "id >= 0"}), "other arguments")
+	assert.Error(t, m.Redeem(token, "s1", "milvus_delete_entities", Target{Address: "localhost:19530", Database: "analytics"}, args), "other database")
+	assert.Error(t, m.Redeem(token, "s1", "milvus_delete_entities", Target{Address: "prod:19530", Database: "default"}, args), "other connection")
+
+	withToken := map[string]any{"filter_expr": "id > 0", "collection_name": "docs", TokenArgument: token}
+	assert.NoError(t, m.Redeem(token, "s1", "milvus_delete_entities", target, withToken))
+	assert.Error(t, m.Redeem(token, "s1", "milvus_delete_entities", target, args), "tokens are single use")
+}
+
+func TestExpiredToken(t *testing.T) {
+	m := NewManager(true, time.Millisecond, nil)
+	args := map[string]any{"collection_name": "docs"}
+
+	token, err := m.Issue("s1", "milvus_drop_collection", target, args)
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	assert.Error(t, m.Redeem(token, "s1", "milvus_drop_collection", target, args))
+}
+
+func TestUnconfirmedTokensAreSwept(t *testing.T) {
+	st := store.NewMemory()
+	m := NewManager(true, time.Millisecond, st)
+	args := map[string]any{"collection_name": "docs"}
+
+	for i := 0; i < 50; i++ {
+		_, err := m.Issue("s1", "milvus_drop_collection", target, args)
+		require.NoError(t, err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// Later previews sweep the tokens that were never confirmed
+	require.Eventually(t, func() bool {
+		_, err := m.Issue("s1", "milvus_drop_collection", target, args)
+		return err == nil && st.Len() < 10
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestSharedTokens(t *testing.T) {
+	st := store.NewMemory()
+	a, b := NewManager(true, time.Minute, st), NewManager(true, time.Minute, st)
+	args := map[string]any{"collection_name": "docs"}
+
+	token, err := a.Issue("s1", "milvus_drop_collection", target, args)
+	require.NoError(t, err)
+	assert.NoError(t, b.Redeem(token, "s1", "milvus_drop_collection", target, args), "any replica redeems the token")
+	assert.Error(t, a.Redeem(token, "s1", "milvus_drop_collection", target, args))
+}
+
+func TestWrap(t *testing.T) {
+	m := NewManager(true, time.Minute, nil)
+	executed := 0
+	handler := m.Wrap(fakePreviewer{}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		executed++
+		return mcp.NewToolResultText("deleted"), nil
+	})
+
+	s := server.NewMCPServer("test", "0.0.0")
+	ctx := session.WithCallState(s.WithContext(context.Background(), &fakeSession{id: "s1"}))
+	session.SetCallState(ctx, &session.SessionState{SessionID: "s1", ConnConfig: &session.ConnConfig{Address: "localhost:19530"}})
+	args := map[string]any{"collection_name": "docs", "filter_expr": "id > 0"}
+
+	dryRun := map[string]any{"collection_name": "docs", "filter_expr": "id > 0", DryRunArgument: true}
+	result, err := handler(ctx, callRequest("milvus_delete_entities", dryRun))
+	require.NoError(t, err)
+	assert.Contains(t, resultText(t, result), "would delete 42 entities")
+	assert.NotContains(t, resultText(t, result), TokenArgument)
+
+	result, err = handler(ctx, callRequest("milvus_delete_entities", args))
+	require.NoError(t, err)
+	preview := resultText(t, result)
+	assert.Contains(t, preview, "would delete 42 entities")
+	assert.Equal(t, 0, executed, "the first call must not execute")
+
+	token := regexp.MustCompile(`"([0-9a-f]{32})"`).FindStringSubmatch(preview)
+	require.Len(t, token, 2)
+
+	confirmed := map[string]any{"collection_name": "docs", "filter_expr": "id > 0", TokenArgument: token[1]}
+	session.SetCallState(ctx, &session.SessionState{SessionID: "s1", ConnConfig: &session.ConnConfig{Address: "localhost:19530", DBName: "analytics"}})
+	result, err = handler(ctx, callRequest("milvus_delete_entities", confirmed))
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "the session switched database since the preview")
+	assert.Equal(t, 0, executed)
+
+	session.SetCallState(ctx, &session.SessionState{SessionID: "s1", ConnConfig: &session.ConnConfig{Address: "localhost:19530"}})
+	result, err = handler(ctx, callRequest("milvus_delete_entities", confirmed))
+	require.NoError(t, err)
+	assert.Equal(t, "deleted", resultText(t, result))
+	assert.Equal(t, 1, executed)
+
+	result, err = handler(ctx, callRequest("milvus_delete_entities", confirmed))
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "a token cannot be replayed")
+	assert.Equal(t, 1, executed)
+}
+
+func TestWithConfirmation(t *testing.T) {
+	tool := NewManager(true, time.Minute, nil).WithConfirmation(mcp.NewTool("milvus_drop_collection",
+		mcp.WithDescription("Drop a collection."),
+	))
+	assert.Contains(t, tool.InputSchema.Properties, TokenArgument)
+	assert.Contains(t, tool.InputSchema.Properties, DryRunArgument)
+	assert.Contains(t, tool.Description, "destructive")
+}
@@ -0,0 +1,107 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Options configures the health endpoints
+type Options struct {
+	Version string
+	Commit  string
+	// Draining reports whether the server is shutting down and should receive no new clients
+	Draining func() bool
+	// Probe checks the Milvus servers behind the connection profiles, returning the errors
+	// by profile name. Nil skips the check.
+	Probe        func(ctx context.Context) map[string]error
+	ProbeTimeout time.Duration
+	// ProbeInterval is how long a probe result is reused, 0 probes on every request
+	ProbeInterval time.Duration
+}
+
+// Handler serves the unauthenticated probe endpoints:
+//
+//	GET /healthz  the process is up
+//	GET /readyz   the server accepts new clients and, if probed, its profiles answer
+//	GET /version  the version and commit of the build
+func Handler(opts Options) http.Handler {
+	h := &handler{opts: opts}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", h.healthz)
+	mux.HandleFunc("GET /readyz", h.readyz)
+	mux.HandleFunc("GET /version", h.version)
+	return mux
+}
+
+// Paths are the paths served by Handler, so transports can route them around authentication
+var Paths = []string{"/healthz", "/readyz", "/version"}
+
+type handler struct {
+	opts Options
+
+	// probeMu serializes probes, so concurrent requests share one result
+	probeMu  sync.Mutex
+	failed   map[string]error
+	probedAt time.Time
+}
+
+func (h *handler) healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *handler) readyz(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Draining != nil && h.opts.Draining() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "draining"})
+		return
+	}
+
+	if h.opts.Probe != nil {
+		if failed := h.probe(r.Context()); len(failed) > 0 {
+			profiles := make(map[string]string, len(failed))
+			for name, err := range failed {
+				profiles[name] = err.Error()
+			}
+			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"status": "unavailable", "profiles": profiles})
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+// probe returns the failed profiles, probing again once the last result is older than
+// ProbeInterval. The probe outlives a canceled request so its result can be shared.
+func (h *handler) probe(ctx context.Context) map[string]error {
+	h.probeMu.Lock()
+	defer h.probeMu.Unlock()
+
+	if !h.probedAt.IsZero() && time.Since(h.probedAt) < h.opts.ProbeInterval {
+		return h.failed
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	if h.opts.ProbeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.opts.ProbeTimeout)
+		defer cancel()
+	}
+	h.failed = h.opts.Probe(ctx)
+	h.probedAt = time.Now()
+	return h.failed
+}
+
+func (h *handler) version(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"version": h.opts.Version, "commit": h.opts.Commit})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logrus.WithError(err).Warn("Failed to write health response")
+	}
+}
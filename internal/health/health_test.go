@@ -0,0 +1,72 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func get(h http.Handler, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestHealthz(t *testing.T) {
+	h := Handler(Options{Draining: func() bool { return true }})
+	assert.Equal(t, http.StatusOK, get(h, "/healthz").Code, "liveness does not depend on draining")
+}
+
+func TestReadyz(t *testing.T) {
+	draining := false
+	var failed map[string]error
+	h := Handler(Options{
+		Draining: func() bool { return draining },
+		Probe:    func(context.Context) map[string]error { return failed },
+	})
+	assert.Equal(t, http.StatusOK, get(h, "/readyz").Code)
+
+	failed = map[string]error{"prod": errors.New("connection refused")}
+	rec := get(h, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	var body struct {
+		Profiles map[string]string `json:"profiles"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "connection refused", body.Profiles["prod"])
+
+	failed = nil
+	draining = true
+	assert.Equal(t, http.StatusServiceUnavailable, get(h, "/readyz").Code)
+}
+
+func TestReadyzReusesProbe(t *testing.T) {
+	probes := 0
+	h := Handler(Options{
+		Probe: func(context.Context) map[string]error {
+			probes++
+			return nil
+		},
+		ProbeInterval: time.Hour,
+	})
+	assert.Equal(t, http.StatusOK, get(h, "/readyz").Code)
+	assert.Equal(t, http.StatusOK, get(h, "/readyz").Code)
+	assert.Equal(t, 1, probes, "the result is reused within the interval")
+}
+
+func TestVersion(t *testing.T) {
+	rec := get(Handler(Options{Version: "v1.2.0", Commit: "abc123"}), "/version")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "v1.2.0", body["version"])
+	assert.Equal(t, "abc123", body["commit"])
+}
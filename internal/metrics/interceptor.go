@@ -0,0 +1,17 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryClientInterceptor observes the latency of every Milvus RPC attempt
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	start := time.Now()
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	MilvusRequestDuration.WithLabelValues(rpcMethod(method), status.Code(err).String()).Observe(time.Since(start).Seconds())
+	return err
+}
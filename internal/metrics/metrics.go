@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "mcp_milvus"
+
+// Registry holds the server's metrics, separate from the default registry so
+// dependencies cannot add their own
+var Registry = prometheus.NewRegistry()
+
+var (
+	// ToolCalls counts completed tool calls by tool and result, success or error
+	ToolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_calls_total",
+		Help:      "Tool calls by tool and result.",
+	}, []string{"tool", "result"})
+
+	// ToolCallDuration observes the latency of tool calls by tool
+	ToolCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_call_duration_seconds",
+		Help:      "Latency of tool calls by tool.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300},
+	}, []string{"tool"})
+
+	// SessionEvents counts session creations, removals and expirations
+	SessionEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "session_events_total",
+		Help:      "Session lifecycle events by event.",
+	}, []string{"event"})
+
+	// MilvusRequestDuration observes each Milvus RPC attempt by method and gRPC code
+	MilvusRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "milvus_request_duration_seconds",
+		Help:      "Latency of Milvus RPCs by method and gRPC status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	// RowsInserted counts the rows written by insert and upsert tools
+	RowsInserted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rows_inserted_total",
+		Help:      "Rows inserted or upserted by tool.",
+	}, []string{"tool"})
+
+	// RowsReturned counts the rows returned by query and search tools
+	RowsReturned = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rows_returned_total",
+		Help:      "Rows returned by query and search tools by tool.",
+	}, []string{"tool"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		ToolCalls,
+		ToolCallDuration,
+		SessionEvents,
+		MilvusRequestDuration,
+		RowsInserted,
+		RowsReturned,
+	)
+}
+
+// RegisterActiveSessions exports the number of sessions reported by count
+func RegisterActiveSessions(count func() int) {
+	Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_sessions",
+		Help:      "Sessions currently holding a Milvus connection.",
+	}, func() float64 { return float64(count()) }))
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// rpcMethod strips the service from a full gRPC method name, "/pkg.Service/Search" becomes "Search"
+func rpcMethod(fullMethod string) string {
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[i+1:]
+	}
+	return fullMethod
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return status.Error(codes.Unavailable, "connection refused")
+	}
+	err := UnaryClientInterceptor(context.Background(), "/milvus.proto.milvus.MilvusService/Search", nil, nil, nil, invoker)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	count := testutil.CollectAndCount(MilvusRequestDuration, namespace+"_milvus_request_duration_seconds")
+	assert.Equal(t, 1, count)
+	assert.Equal(t, "Search", rpcMethod("/milvus.proto.milvus.MilvusService/Search"))
+	assert.Equal(t, "Search", rpcMethod("Search"))
+}
+
+func TestHandler(t *testing.T) {
+	ToolCalls.WithLabelValues("milvus_query", "success").Inc()
+	RowsReturned.WithLabelValues("milvus_query").Add(3)
+	RegisterActiveSessions(func() int { return 2 })
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	require.Equal(t, 200, rec.Code)
+
+	body := rec.Body.String()
+	assert.Contains(t, body, `mcp_milvus_tool_calls_total{result="success",tool="milvus_query"} 1`)
+	assert.Contains(t, body, `mcp_milvus_rows_returned_total{tool="milvus_query"} 3`)
+	assert.Contains(t, body, "mcp_milvus_active_sessions 2")
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/tailabs/mcp-milvus/internal/audit"
+	"github.com/tailabs/mcp-milvus/internal/auth"
+	"github.com/tailabs/mcp-milvus/internal/session"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Audit records every tool call, including calls rejected by Auth and Authorize,
+// so it must run before every middleware but CallState
+func Audit(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (cr *mcp.CallToolResult, err error) {
+		auditor := audit.GetAuditor()
+		if !auditor.Enabled() {
+			return next(ctx, req)
+		}
+
+		start := time.Now()
+		defer func() {
+			event := &audit.Event{
+				Time:       start,
+				SessionID:  sessionIDFromContext(ctx),
+				Tool:       req.Params.Name,
+				Arguments:  req.GetArguments(),
+				DurationMs: time.Since(start).Milliseconds(),
+				Success:    err == nil && cr != nil && !cr.IsError,
+			}
+			if principal := auth.PrincipalFromContext(ctx); principal != nil {
+				event.Principal = principal.Subject
+			}
+			for _, key := range collectionArguments {
+				if name, ok := event.Arguments[key].(string); ok {
+					event.Collection = name
+					break
+				}
+			}
+
+			// Read the connection after the call, so milvus_connector records the new one
+			if state, stateErr := session.CallState(ctx, event.SessionID); stateErr == nil {
+				event.Address = state.ConnConfig.Address
+				event.Database = callDatabase(req, state)
+				event.Profile = state.ConnConfig.Profile
+			}
+
+			text := resultText(cr)
+			event.ResultSize = len(text)
+			if err != nil {
+				event.Error = err.Error()
+			} else if cr != nil && cr.IsError {
+				event.Error = text
+			}
+
+			auditor.Record(event)
+		}()
+
+		return next(ctx, req)
+	}
+}
+
+// resultText concatenates the text content of a tool result
+func resultText(cr *mcp.CallToolResult) string {
+	if cr == nil {
+		return ""
+	}
+	var text string
+	for _, content := range cr.Content {
+		if c, ok := content.(mcp.TextContent); ok {
+			text += c.Text
+		}
+	}
+	return text
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/tailabs/mcp-milvus/internal/audit"
+	"github.com/tailabs/mcp-milvus/internal/auth"
+	"github.com/tailabs/mcp-milvus/internal/session"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memorySink struct {
+	mu     sync.Mutex
+	events []*audit.Event
+}
+
+func (s *memorySink) Write(event *audit.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *memorySink) Close() error { return nil }
+
+// recordAudit registers a sink on the global auditor until the test ends
+func recordAudit(t *testing.T) *memorySink {
+	t.Helper()
+	sink := &memorySink{}
+	audit.GetAuditor().AddSink(sink)
+	t.Cleanup(func() { require.NoError(t, audit.GetAuditor().Close()) })
+	return sink
+}
+
+func TestAudit(t *testing.T) {
+	analytics := &session.ConnConfig{Address: "localhost:19530", DBName: "analytics"}
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		tool      stubTool
+		args      map[string]any
+		want      audit.Event
+	}{
+		{
+			name:      "success",
+			principal: &auth.Principal{Subject: "alice"},
+			args:      map[string]any{"collection_name": "docs", "limit": 10},
+			want: audit.Event{Principal: "alice", SessionID: "audit-s1", Address: "localhost:19530", Database: "analytics",
+				Tool: "milvus_query", Arguments: map[string]any{"collection_name": "docs", "limit": 10}, Collection: "docs", ResultSize: 2, Success: true},
+		},
+		{
+			name: "tool error",
+			tool: stubTool{result: mcp.NewToolResultError("collection not found")},
+			args: map[string]any{"collection_name": "users"},
+			want: audit.Event{SessionID: "audit-s1", Address: "localhost:19530", Database: "analytics", Tool: "milvus_query",
+				Arguments: map[string]any{"collection_name": "users"}, Collection: "users", ResultSize: 20, Error: "collection not found"},
+		},
+		{
+			name: "secret arguments",
+			args: map[string]any{"password": "hunter2", "db_name": "billing"},
+			want: audit.Event{SessionID: "audit-s1", Address: "localhost:19530", Database: "billing", Tool: "milvus_query",
+				Arguments: map[string]any{"password": "******", "db_name": "billing"}, ResultSize: 2, Success: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := recordAudit(t)
+			ctx := withConnection(callContext("audit-s1", tt.principal), "audit-s1", analytics)
+			_, err := Audit(tt.tool.handle)(ctx, callRequest("milvus_query", tt.args))
+			require.NoError(t, err)
+
+			require.Len(t, sink.events, 1)
+			event := *sink.events[0]
+			event.Time, event.DurationMs = tt.want.Time, tt.want.DurationMs
+			assert.Equal(t, tt.want, event)
+		})
+	}
+}
+
+// Audit runs before Auth, so calls Auth rejects are recorded too
+func TestAuditRecordsRejectedCalls(t *testing.T) {
+	sink := recordAudit(t)
+	tool := &stubTool{}
+
+	_, err := chain(tool.handle)(callContext("", nil), callRequest("milvus_query", map[string]any{"token": "s3cret"}))
+	require.NoError(t, err)
+	assert.Equal(t, 0, tool.calls)
+
+	require.Len(t, sink.events, 1)
+	assert.False(t, sink.events[0].Success)
+	assert.Equal(t, "must provide an available session id", sink.events[0].Error)
+	assert.Equal(t, map[string]any{"token": "******"}, sink.events[0].Arguments)
+}
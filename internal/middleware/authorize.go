@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tailabs/mcp-milvus/internal/auth"
+	"github.com/tailabs/mcp-milvus/internal/policy"
+	"github.com/tailabs/mcp-milvus/internal/session"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var (
+	// databaseArguments name the tool arguments holding a database name
+	databaseArguments = []string{"db_name", "database_name"}
+	// collectionArguments name the tool arguments holding a collection name
+	collectionArguments = []string{"collection_name", "old_collection_name", "new_collection_name"}
+)
+
+// Authorize enforces the caller's policy on the tool, its database and collection
+// arguments and the session's current database. The policy is passed on in the
+// context so tools can narrow their results to what the caller may see.
+func Authorize(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		sessionID := sessionIDFromContext(ctx)
+
+		profile := sessionProfile(ctx, sessionID)
+		if req.Params.Name == "milvus_connector" {
+			// The connector picks the profile the session is about to use
+			profile = req.GetString("profile", "")
+		}
+
+		p := policy.Resolve(auth.PrincipalFromContext(ctx), profile)
+		if p == nil {
+			return next(ctx, req)
+		}
+
+		if !p.AllowsTool(req.Params.Name) {
+			return mcp.NewToolResultError(fmt.Sprintf("tool %s is not permitted by policy %s", req.Params.Name, p.Name)), nil
+		}
+
+		args := req.GetArguments()
+		for _, key := range databaseArguments {
+			if name, ok := args[key].(string); ok && !p.AllowsDatabase(name) {
+				return mcp.NewToolResultError(fmt.Sprintf("database %s is not permitted by policy %s", name, p.Name)), nil
+			}
+		}
+		for _, key := range collectionArguments {
+			if name, ok := args[key].(string); ok && !p.AllowsCollection(name) {
+				return mcp.NewToolResultError(fmt.Sprintf("collection %s is not permitted by policy %s", name, p.Name)), nil
+			}
+		}
+
+		if req.Params.Name != "milvus_connector" {
+			if state, err := session.CallState(ctx, sessionID); err == nil && !p.AllowsDatabase(state.ConnConfig.DBName) {
+				database := state.ConnConfig.DBName
+				if database == "" {
+					database = "default"
+				}
+				return mcp.NewToolResultError(fmt.Sprintf("database %s is not permitted by policy %s", database, p.Name)), nil
+			}
+		}
+
+		return next(policy.WithPolicy(ctx, p), req)
+	}
+}
+
+// FilterTools hides the tools the caller's policy does not permit from tools/list
+func FilterTools(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	p := policy.Resolve(auth.PrincipalFromContext(ctx), sessionProfile(ctx, sessionIDFromContext(ctx)))
+	if p == nil {
+		return tools
+	}
+
+	allowed := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if p.AllowsTool(tool.Name) {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed
+}
+
+// sessionProfile returns the connection profile the session is attached to, or the
+// default profile it will attach to on first use
+func sessionProfile(ctx context.Context, sessionID string) string {
+	if sessionID == "" {
+		return ""
+	}
+	state, err := session.CallState(ctx, sessionID)
+	if err != nil {
+		return session.DefaultProfile()
+	}
+	return state.ConnConfig.Profile
+}
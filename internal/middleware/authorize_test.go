@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/tailabs/mcp-milvus/internal/auth"
+	"github.com/tailabs/mcp-milvus/internal/policy"
+	"github.com/tailabs/mcp-milvus/internal/session"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configurePolicies installs a policy engine until the test ends
+func configurePolicies(t *testing.T, cfg policy.Config) {
+	t.Helper()
+	e, err := policy.NewEngine(cfg)
+	require.NoError(t, err)
+	policy.SetEngine(e)
+	t.Cleanup(func() { policy.SetEngine(nil) })
+}
+
+var analystPolicies = policy.Config{
+	Policies: map[string]*policy.Policy{
+		"analyst": {
+			AllowTools:  []string{"milvus_query", "milvus_list_*"},
+			Databases:   []string{"analytics"},
+			Collections: []string{"docs*"},
+		},
+		"staging": {DenyTools: []string{"milvus_drop_collection"}},
+	},
+	Principals: map[string]string{"alice": "analyst"},
+	Profiles:   map[string]string{"staging": "staging"},
+}
+
+func TestAuthorize(t *testing.T) {
+	configurePolicies(t, analystPolicies)
+	alice := &auth.Principal{Subject: "alice"}
+	analytics := &session.ConnConfig{Address: "localhost:19530", DBName: "analytics"}
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		conn      *session.ConnConfig
+		tool      string
+		args      map[string]any
+		// policy is the policy passed on to the tool, "" for none
+		policy   string
+		rejected string
+	}{
+		{name: "unrestricted caller", conn: analytics, tool: "milvus_drop_collection"},
+		{name: "permitted call", principal: alice, conn: analytics, tool: "milvus_query", args: map[string]any{"collection_name": "docs_2024"}, policy: "analyst"},
+		{name: "tool", principal: alice, conn: analytics, tool: "milvus_drop_collection", rejected: "tool milvus_drop_collection is not permitted by policy analyst"},
+		{name: "database argument", principal: alice, conn: analytics, tool: "milvus_list_collections", args: map[string]any{"db_name": "billing"}, rejected: "database billing is not permitted"},
+		{name: "collection argument", principal: alice, conn: analytics, tool: "milvus_query", args: map[string]any{"collection_name": "users"}, rejected: "collection users is not permitted"},
+		{name: "session database", principal: alice, conn: &session.ConnConfig{Address: "localhost:19530"}, tool: "milvus_query", args: map[string]any{"collection_name": "docs"}, rejected: "database default is not permitted"},
+		{name: "session profile", conn: &session.ConnConfig{Address: "localhost:19530", Profile: "staging"}, tool: "milvus_drop_collection", rejected: "not permitted by policy staging"},
+		{name: "profile the connector attaches to", tool: "milvus_connector", args: map[string]any{"profile": "staging"}, policy: "staging"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := callContext("authz-s1", tt.principal)
+			if tt.conn != nil {
+				ctx = withConnection(ctx, "authz-s1", tt.conn)
+			}
+			tool := &stubTool{}
+			cr, err := Authorize(tool.handle)(ctx, callRequest(tt.tool, tt.args))
+			require.NoError(t, err)
+
+			if tt.rejected != "" {
+				assert.True(t, cr.IsError)
+				assert.Contains(t, textOf(t, cr), tt.rejected)
+				assert.Equal(t, 0, tool.calls, "rejected calls never reach the tool")
+				return
+			}
+			assert.False(t, cr.IsError)
+			require.Equal(t, 1, tool.calls)
+			if tt.policy == "" {
+				assert.Nil(t, policy.FromContext(tool.ctx))
+			} else {
+				require.NotNil(t, policy.FromContext(tool.ctx), "tools narrow their results to the caller's policy")
+				assert.Equal(t, tt.policy, policy.FromContext(tool.ctx).Name)
+			}
+		})
+	}
+}
+
+func TestFilterTools(t *testing.T) {
+	configurePolicies(t, analystPolicies)
+	tools := []mcp.Tool{mcp.NewTool("milvus_query"), mcp.NewTool("milvus_list_collections"), mcp.NewTool("milvus_drop_collection")}
+
+	var names []string
+	for _, tool := range FilterTools(callContext("authz-s2", &auth.Principal{Subject: "alice"}), tools) {
+		names = append(names, tool.Name)
+	}
+	assert.Equal(t, []string{"milvus_query", "milvus_list_collections"}, names)
+	assert.Len(t, FilterTools(callContext("authz-s2", nil), tools), 3, "unrestricted callers see every tool")
+}
+
+// Authorize runs after RateLimit, so calls the policy rejects still count against the budget
+func TestAuthorizeAfterRateLimit(t *testing.T) {
+	configurePolicies(t, analystPolicies)
+	configureRateLimit(t, oneCall)
+	alice := &auth.Principal{Subject: "alice"}
+	tool := &stubTool{}
+	handler := chain(tool.handle)
+
+	cr, err := handler(callContext("authz-s3", alice), callRequest("milvus_connector", nil))
+	require.NoError(t, err)
+	assert.Contains(t, textOf(t, cr), "not permitted by policy analyst")
+
+	cr, err = handler(callContext("authz-s3", alice), callRequest("milvus_connector", nil))
+	require.NoError(t, err)
+	assert.Contains(t, textOf(t, cr), "rate limit exceeded", "the rejected call used up the budget")
+	assert.Equal(t, 0, tool.calls)
+}
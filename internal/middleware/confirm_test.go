@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/tailabs/mcp-milvus/internal/auth"
+	"github.com/tailabs/mcp-milvus/internal/confirm"
+	"github.com/tailabs/mcp-milvus/internal/session"
+	"github.com/tailabs/mcp-milvus/internal/session/store"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubPreviewer is a previewer counting its calls
+type stubPreviewer struct {
+	calls int
+}
+
+func (p *stubPreviewer) Preview(ctx context.Context, req mcp.CallToolRequest) (string, error) {
+	p.calls++
+	return "drops 42 entities", nil
+}
+
+// Authorize runs before the confirmation, so callers the policy rejects never get a preview or a token
+func TestAuthorizeBeforeConfirmation(t *testing.T) {
+	configurePolicies(t, analystPolicies)
+	st := store.NewMemory()
+	previewer := &stubPreviewer{}
+	tool := &stubTool{}
+	handler := Authorize(confirm.NewManager(true, time.Minute, st).Wrap(previewer, tool.handle))
+	conn := &session.ConnConfig{Address: "localhost:19530", DBName: "analytics"}
+	args := map[string]any{"collection_name": "docs"}
+
+	ctx := withConnection(callContext("confirm-s1", &auth.Principal{Subject: "alice"}), "confirm-s1", conn)
+	cr, err := handler(ctx, callRequest("milvus_drop_collection", args))
+	require.NoError(t, err)
+	assert.True(t, cr.IsError)
+	assert.Contains(t, textOf(t, cr), "not permitted by policy analyst")
+	assert.Equal(t, 0, previewer.calls)
+	assert.Equal(t, 0, st.Len(), "no token is issued")
+
+	ctx = withConnection(callContext("confirm-s2", nil), "confirm-s2", conn)
+	cr, err = handler(ctx, callRequest("milvus_drop_collection", args))
+	require.NoError(t, err)
+	require.False(t, cr.IsError)
+	assert.Equal(t, 1, previewer.calls)
+	assert.Equal(t, 0, tool.calls, "the first call only previews")
+	token := regexp.MustCompile(`confirmation_token: "([0-9a-f]+)"`).FindStringSubmatch(textOf(t, cr))
+	require.Len(t, token, 2)
+
+	ctx = withConnection(callContext("confirm-s2", nil), "confirm-s2", conn)
+	cr, err = handler(ctx, callRequest("milvus_drop_collection", map[string]any{"collection_name": "docs", confirm.TokenArgument: token[1]}))
+	require.NoError(t, err)
+	assert.False(t, cr.IsError)
+	assert.Equal(t, 1, tool.calls, "the confirmed call executes")
+}
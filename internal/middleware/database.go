@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/tailabs/mcp-milvus/internal/session"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// MetaKey is the _meta entry of tool results naming the Milvus address and database
+const MetaKey = "milvus"
+
+// DatabaseMeta adds the Milvus address and database a call ran against to the _meta of
+// its result, so clients always know which database they are looking at
+func DatabaseMeta(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cr, err := next(ctx, req)
+		if err != nil || cr == nil {
+			return cr, err
+		}
+
+		// Read the connection after the call, so milvus_connector and milvus_use_database report the new one
+		state, stateErr := session.CallState(ctx, sessionIDFromContext(ctx))
+		if stateErr != nil {
+			return cr, nil
+		}
+
+		if cr.Meta == nil {
+			cr.Meta = make(map[string]any)
+		}
+		cr.Meta[MetaKey] = map[string]any{
+			"address":  state.ConnConfig.Address,
+			"database": callDatabase(req, state),
+		}
+		return cr, nil
+	}
+}
+
+// callDatabase returns the database a tool call targets, honouring the per-call db_name override
+func callDatabase(req mcp.CallToolRequest, state *session.SessionState) string {
+	if req.Params.Name != "milvus_connector" {
+		if name := req.GetString("db_name", ""); name != "" {
+			return name
+		}
+	}
+	if state.ConnConfig.DBName == "" {
+		return "default"
+	}
+	return state.ConnConfig.DBName
+}
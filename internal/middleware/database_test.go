@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tailabs/mcp-milvus/internal/session"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseMeta(t *testing.T) {
+	analytics := &session.ConnConfig{Address: "localhost:19530", DBName: "analytics"}
+
+	tests := []struct {
+		name string
+		conn *session.ConnConfig
+		tool string
+		args map[string]any
+		// meta is the expected _meta entry, nil for none
+		meta map[string]any
+	}{
+		{name: "session database", conn: analytics, tool: "milvus_query", meta: map[string]any{"address": "localhost:19530", "database": "analytics"}},
+		{name: "default database", conn: &session.ConnConfig{Address: "localhost:19530"}, tool: "milvus_query", meta: map[string]any{"address": "localhost:19530", "database": "default"}},
+		{name: "db_name override", conn: analytics, tool: "milvus_list_collections", args: map[string]any{"db_name": "billing"}, meta: map[string]any{"address": "localhost:19530", "database": "billing"}},
+		{name: "connector reports the new connection", conn: analytics, tool: "milvus_connector", args: map[string]any{"db_name": "billing"}, meta: map[string]any{"address": "localhost:19530", "database": "analytics"}},
+		{name: "not connected", tool: "milvus_query"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := callContext("meta-s1", nil)
+			if tt.conn != nil {
+				ctx = withConnection(ctx, "meta-s1", tt.conn)
+			}
+			cr, err := DatabaseMeta((&stubTool{}).handle)(ctx, callRequest(tt.tool, tt.args))
+			require.NoError(t, err)
+			if tt.meta == nil {
+				assert.Nil(t, cr.Meta)
+				return
+			}
+			assert.Equal(t, tt.meta, cr.Meta[MetaKey])
+		})
+	}
+
+	t.Run("handler errors pass through", func(t *testing.T) {
+		reset := errors.New("connection reset")
+		ctx := withConnection(callContext("meta-s2", nil), "meta-s2", analytics)
+		cr, err := DatabaseMeta((&stubTool{err: reset}).handle)(ctx, callRequest("milvus_query", nil))
+		assert.ErrorIs(t, err, reset)
+		assert.Nil(t, cr)
+	})
+}
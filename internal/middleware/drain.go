@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// calls tracks the tool calls in flight so shutdown can wait for them
+var calls = &callTracker{idle: make(chan struct{})}
+
+type callTracker struct {
+	mu       sync.Mutex
+	inFlight int
+	draining bool
+	// idle is closed while no call is in flight
+	idle chan struct{}
+}
+
+func init() {
+	close(calls.idle)
+}
+
+// Drain rejects new tool calls once the server is shutting down and tracks the running ones
+func Drain(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls.mu.Lock()
+		if calls.draining {
+			calls.mu.Unlock()
+			return mcp.NewToolResultError("server is shutting down, reconnect and retry the call"), nil
+		}
+		if calls.inFlight == 0 {
+			calls.idle = make(chan struct{})
+		}
+		calls.inFlight++
+		calls.mu.Unlock()
+
+		defer func() {
+			calls.mu.Lock()
+			calls.inFlight--
+			if calls.inFlight == 0 {
+				close(calls.idle)
+			}
+			calls.mu.Unlock()
+		}()
+
+		return next(ctx, req)
+	}
+}
+
+// StartDraining makes Drain reject new tool calls, Draining reports true from then on
+func StartDraining() {
+	calls.mu.Lock()
+	defer calls.mu.Unlock()
+	calls.draining = true
+}
+
+// Draining reports whether the server stopped accepting tool calls
+func Draining() bool {
+	calls.mu.Lock()
+	defer calls.mu.Unlock()
+	return calls.draining
+}
+
+// WaitForCalls blocks until no tool call is in flight, returning the number of calls
+// still running if ctx ends first
+func WaitForCalls(ctx context.Context) (int, error) {
+	calls.mu.Lock()
+	idle := calls.idle
+	calls.mu.Unlock()
+
+	select {
+	case <-idle:
+		return 0, nil
+	case <-ctx.Done():
+		calls.mu.Lock()
+		defer calls.mu.Unlock()
+		return calls.inFlight, ctx.Err()
+	}
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startDraining drains like a shutdown and undoes it when the test ends
+func startDraining(t *testing.T) {
+	t.Helper()
+	StartDraining()
+	t.Cleanup(func() {
+		calls.mu.Lock()
+		defer calls.mu.Unlock()
+		calls.draining = false
+	})
+}
+
+func TestDrain(t *testing.T) {
+	tests := []struct {
+		name     string
+		draining bool
+	}{
+		{name: "serving"},
+		{name: "draining", draining: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.draining {
+				startDraining(t)
+			}
+			tool := &stubTool{}
+			cr, err := Drain(tool.handle)(callContext("drain-s1", nil), callRequest("milvus_query", nil))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.draining, cr.IsError)
+			assert.Equal(t, tt.draining, Draining())
+			if tt.draining {
+				assert.Contains(t, textOf(t, cr), "shutting down")
+				assert.Equal(t, 0, tool.calls)
+			} else {
+				assert.Equal(t, 1, tool.calls)
+			}
+		})
+	}
+}
+
+func TestWaitForCalls(t *testing.T) {
+	started, finish := make(chan struct{}), make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Drain(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			close(started)
+			<-finish
+			return mcp.NewToolResultText("ok"), nil
+		})(callContext("drain-s2", nil), callRequest("milvus_insert_data", nil))
+	}()
+	<-started
+	startDraining(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	running, err := WaitForCalls(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, running, "the call started before draining is still running")
+
+	close(finish)
+	<-done
+	running, err = WaitForCalls(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, running)
+}
+
+// Drain runs before Auth, so during shutdown calls are refused without touching the session store
+func TestDrainBeforeAuth(t *testing.T) {
+	startDraining(t)
+	tool := &stubTool{}
+	cr, err := chain(tool.handle)(callContext("", nil), callRequest("milvus_query", nil))
+	require.NoError(t, err)
+	assert.Contains(t, textOf(t, cr), "shutting down", "Auth would have rejected the call without a session")
+	assert.Equal(t, 0, tool.calls)
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/tailabs/mcp-milvus/internal/metrics"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Metrics counts tool calls and observes their latency, including calls rejected by later middlewares
+func Metrics(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (cr *mcp.CallToolResult, err error) {
+		start := time.Now()
+		defer func() {
+			result := "success"
+			if err != nil || cr == nil || cr.IsError {
+				result = "error"
+			}
+			metrics.ToolCalls.WithLabelValues(req.Params.Name, result).Inc()
+			metrics.ToolCallDuration.WithLabelValues(req.Params.Name).Observe(time.Since(start).Seconds())
+		}()
+
+		return next(ctx, req)
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tailabs/mcp-milvus/internal/metrics"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	tests := []struct {
+		name   string
+		tool   stubTool
+		result string
+	}{
+		{name: "success", result: "success"},
+		{name: "tool error", tool: stubTool{result: mcp.NewToolResultError("collection not found")}, result: "error"},
+		{name: "handler error", tool: stubTool{err: errors.New("connection reset")}, result: "error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := "metrics_" + tt.result
+			calls := metrics.ToolCalls.WithLabelValues(tool, tt.result)
+			before := testutil.ToFloat64(calls)
+
+			_, _ = Metrics(tt.tool.handle)(callContext("metrics-s1", nil), callRequest(tool, nil))
+			assert.Equal(t, before+1, testutil.ToFloat64(calls))
+			assert.Equal(t, 1, tt.tool.calls)
+		})
+	}
+}
+
+// Metrics runs before the middlewares that reject calls, so rejections count as errors
+func TestMetricsCountRejectedCalls(t *testing.T) {
+	calls := metrics.ToolCalls.WithLabelValues("metrics_rejected", "error")
+	before := testutil.ToFloat64(calls)
+	tool := &stubTool{}
+
+	cr, err := chain(tool.handle)(callContext("", nil), callRequest("metrics_rejected", nil))
+	require.NoError(t, err)
+	assert.Contains(t, textOf(t, cr), "must provide an available session id")
+	assert.Equal(t, before+1, testutil.ToFloat64(calls))
+	assert.Equal(t, 0, tool.calls)
+}
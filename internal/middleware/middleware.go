@@ -4,22 +4,57 @@ import (
 	"context"
 	"time"
 
+	"github.com/tailabs/mcp-milvus/internal/auth"
 	"github.com/tailabs/mcp-milvus/internal/session"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// Chain returns the tool middlewares in the order they run, the first one outermost.
+// Audit and Metrics come first so they see the calls rejected further in, Drain
+// rejects calls during shutdown before Auth loads the session, and RateLimit charges
+// every call before Authorize so probing forbidden tools is not free.
+func Chain() []server.ToolHandlerMiddleware {
+	return []server.ToolHandlerMiddleware{
+		CallState,
+		Audit,
+		Metrics,
+		Drain,
+		Tracing,
+		Logging,
+		Auth,
+		RateLimit,
+		Authorize,
+		Timeout,
+		DatabaseMeta,
+	}
+}
+
+// slowCallThreshold is the duration after which a completed tool call is logged as slow
+var slowCallThreshold = 10 * time.Second
+
+// SetSlowCallThreshold configures when Logging warns about slow tool calls, 0 disables the warning
+func SetSlowCallThreshold(threshold time.Duration) {
+	slowCallThreshold = threshold
+}
+
 func Logging(next server.ToolHandlerFunc) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (cr *mcp.CallToolResult, err error) {
 		start := time.Now()
-		sessionID := server.ClientSessionFromContext(ctx)
 
 		l := logrus.WithFields(logrus.Fields{
-			"session": sessionID.SessionID(),
+			"session": sessionIDFromContext(ctx),
 			"tool":    req.Params.Name,
 		})
+		if principal := auth.PrincipalFromContext(ctx); principal != nil {
+			l = l.WithField("principal", principal.Subject)
+		}
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			l = l.WithField("trace_id", span.TraceID().String())
+		}
 
 		defer func() {
 			duration := time.Since(start)
@@ -27,6 +62,8 @@ func Logging(next server.ToolHandlerFunc) server.ToolHandlerFunc {
 				l.WithField("duration", duration).Errorf("Tool call failed, %v", err)
 			} else if cr != nil && cr.IsError {
 				l.WithField("duration", duration).Errorf("Tool call failed, %#+v", cr)
+			} else if slowCallThreshold > 0 && duration > slowCallThreshold {
+				l.WithField("duration", duration).Warn("Tool call completed slowly")
 			} else {
 				l.WithField("duration", duration).Info("Tool call completed")
 			}
@@ -36,21 +73,60 @@ func Logging(next server.ToolHandlerFunc) server.ToolHandlerFunc {
 	}
 }
 
+// CallState lets Auth share the session state it loads with the later middlewares and
+// the tool, it runs first so that Audit sees the state as well
+func CallState(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return next(session.WithCallState(ctx), req)
+	}
+}
+
 func Auth(next server.ToolHandlerFunc) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		sessionClient := server.ClientSessionFromContext(ctx)
-		if sessionClient == nil || sessionClient.SessionID() == "" {
+		// Every transport binds a client session to the context: SSE uses one per
+		// connection, stdio a single implicit session for the lifetime of the process
+		sessionID := sessionIDFromContext(ctx)
+		if sessionID == "" {
 			return mcp.NewToolResultError("must provide an available session id"), nil
 		}
 
+		if session.GetSessionManager().IsDisconnected(sessionID) {
+			return mcp.NewToolResultError("session was disconnected by an operator, start a new session"), nil
+		}
+
+		// With inbound auth enabled, a session may only be used by the principal that created it
+		if err := session.BindPrincipal(sessionID, auth.PrincipalFromContext(ctx)); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
 		if req.Params.Name == "milvus_connector" {
 			return next(ctx, req)
 		}
 
-		_, err := session.GetSessionManager().Get(sessionClient.SessionID())
+		state, err := session.GetSessionManager().Access(sessionID)
 		if err != nil {
-			return mcp.NewToolResultError("auth first, please call milvus_connector tool"), nil
+			// Sessions attach to the default connection profile on first use
+			attached, attachErr := session.AttachDefaultProfile(sessionID)
+			if attachErr != nil {
+				return mcp.NewToolResultError("failed to connect with the default profile: " + attachErr.Error()), nil
+			}
+			if !attached {
+				return mcp.NewToolResultError("auth first, please call milvus_connector tool"), nil
+			}
+			if state, err = session.GetSessionManager().Access(sessionID); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 		}
+		session.SetCallState(ctx, state)
 		return next(ctx, req)
 	}
 }
+
+// sessionIDFromContext returns the MCP client session ID bound to ctx, or "" if there is none
+func sessionIDFromContext(ctx context.Context) string {
+	sessionClient := server.ClientSessionFromContext(ctx)
+	if sessionClient == nil {
+		return ""
+	}
+	return sessionClient.SessionID()
+}
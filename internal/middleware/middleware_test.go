@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tailabs/mcp-milvus/internal/auth"
+	"github.com/tailabs/mcp-milvus/internal/session"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSession struct {
+	id string
+}
+
+func (s *fakeSession) Initialize()                                         {}
+func (s *fakeSession) Initialized() bool                                   { return true }
+func (s *fakeSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s *fakeSession) SessionID() string                                   { return s.id }
+
+var testServer = server.NewMCPServer("test", "0.0.0")
+
+// callContext returns the context of a tool call on the session by the principal,
+// an empty id binds no session and a nil principal is an anonymous caller
+func callContext(id string, principal *auth.Principal) context.Context {
+	ctx := context.Background()
+	if id != "" {
+		ctx = testServer.WithContext(ctx, &fakeSession{id: id})
+	}
+	if principal != nil {
+		ctx = auth.WithPrincipal(ctx, principal)
+	}
+	return session.WithCallState(ctx)
+}
+
+// withConnection records the session state Auth would load, for calls on a connected session
+func withConnection(ctx context.Context, id string, config *session.ConnConfig) context.Context {
+	session.SetCallState(ctx, &session.SessionState{SessionID: id, ConnConfig: config})
+	return ctx
+}
+
+func callRequest(tool string, args map[string]any) mcp.CallToolRequest {
+	var req mcp.CallToolRequest
+	req.Params.Name = tool
+	req.Params.Arguments = args
+	return req
+}
+
+// stubTool is a tool handler counting its calls
+type stubTool struct {
+	calls  int
+	ctx    context.Context
+	result *mcp.CallToolResult
+	err    error
+}
+
+func (s *stubTool) handle(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.calls++
+	s.ctx = ctx
+	if s.result != nil || s.err != nil {
+		return s.result, s.err
+	}
+	return mcp.NewToolResultText("ok"), nil
+}
+
+// chain wraps handler in Chain the way the MCP server does, the first middleware outermost
+func chain(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	middlewares := Chain()
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+func textOf(t *testing.T, cr *mcp.CallToolResult) string {
+	t.Helper()
+	require.NotNil(t, cr)
+	require.Len(t, cr.Content, 1)
+	text, ok := cr.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	return text.Text
+}
+
+func TestAuth(t *testing.T) {
+	alice := &auth.Principal{Subject: "alice"}
+	require.NoError(t, session.BindPrincipal("auth-owned", alice))
+
+	tests := []struct {
+		name      string
+		session   string
+		principal *auth.Principal
+		tool      string
+		rejected  string
+	}{
+		{name: "no session", tool: "milvus_connector", rejected: "must provide an available session id"},
+		{name: "connector before connecting", session: "auth-new", tool: "milvus_connector"},
+		{name: "tool before connecting", session: "auth-new", tool: "milvus_query", rejected: "auth first"},
+		{name: "owner", session: "auth-owned", principal: alice, tool: "milvus_connector"},
+		{name: "other principal", session: "auth-owned", principal: &auth.Principal{Subject: "mallory"}, tool: "milvus_connector", rejected: "belongs to another principal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := &stubTool{}
+			cr, err := Auth(tool.handle)(callContext(tt.session, tt.principal), callRequest(tt.tool, nil))
+			require.NoError(t, err)
+
+			if tt.rejected == "" {
+				assert.False(t, cr.IsError)
+				assert.Equal(t, 1, tool.calls)
+				return
+			}
+			assert.True(t, cr.IsError)
+			assert.Contains(t, textOf(t, cr), tt.rejected)
+			assert.Equal(t, 0, tool.calls, "rejected calls never reach the tool")
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/tailabs/mcp-milvus/internal/auth"
+	"github.com/tailabs/mcp-milvus/internal/ratelimit"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/sirupsen/logrus"
+)
+
+// RateLimit enforces the call budgets and concurrency limit of the caller. Authenticated
+// callers share one budget across all their sessions, anonymous ones get one per session.
+func RateLimit(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		limiter := ratelimit.GetLimiter()
+		if !limiter.Enabled() {
+			return next(ctx, req)
+		}
+
+		key := "session " + sessionIDFromContext(ctx)
+		if principal := auth.PrincipalFromContext(ctx); principal != nil {
+			key = "principal " + principal.Subject
+		}
+
+		release, err := limiter.Acquire(key, req.Params.Name)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"tool":  req.Params.Name,
+				"error": err,
+			}).Warn("Tool call rate limited")
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer release()
+
+		return next(ctx, req)
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tailabs/mcp-milvus/internal/auth"
+	"github.com/tailabs/mcp-milvus/internal/ratelimit"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configureRateLimit replaces the global limiter until the test ends
+func configureRateLimit(t *testing.T, opts ratelimit.Options) {
+	t.Helper()
+	ratelimit.Configure(opts)
+	t.Cleanup(func() { ratelimit.Configure(ratelimit.Options{}) })
+}
+
+// oneCall allows a single call per caller until the bucket refills minutes later
+var oneCall = ratelimit.Options{CallsPerSecond: 0.001, Burst: 1}
+
+func TestRateLimitKeys(t *testing.T) {
+	alice := &auth.Principal{Subject: "alice"}
+
+	tests := []struct {
+		name   string
+		opts   ratelimit.Options
+		first  context.Context
+		second context.Context
+		// limited reports whether the second call is rejected
+		limited bool
+	}{
+		{name: "disabled", first: callContext("rl-s1", nil), second: callContext("rl-s1", nil)},
+		{name: "same anonymous session", opts: oneCall, first: callContext("rl-s1", nil), second: callContext("rl-s1", nil), limited: true},
+		{name: "anonymous sessions have their own budget", opts: oneCall, first: callContext("rl-s1", nil), second: callContext("rl-s2", nil)},
+		{name: "a principal shares its budget across sessions", opts: oneCall, first: callContext("rl-s1", alice), second: callContext("rl-s2", alice), limited: true},
+		{name: "principals have their own budget", opts: oneCall, first: callContext("rl-s1", alice), second: callContext("rl-s1", &auth.Principal{Subject: "bob"})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configureRateLimit(t, tt.opts)
+			tool := &stubTool{}
+			handler := RateLimit(tool.handle)
+
+			cr, err := handler(tt.first, callRequest("milvus_query", nil))
+			require.NoError(t, err)
+			assert.False(t, cr.IsError)
+
+			cr, err = handler(tt.second, callRequest("milvus_query", nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.limited, cr.IsError)
+			if tt.limited {
+				assert.Contains(t, textOf(t, cr), "retry")
+				assert.Equal(t, 1, tool.calls)
+			} else {
+				assert.Equal(t, 2, tool.calls)
+			}
+		})
+	}
+}
+
+func TestRateLimitConcurrency(t *testing.T) {
+	configureRateLimit(t, ratelimit.Options{MaxConcurrent: 1})
+
+	var nested *mcp.CallToolResult
+	var handler func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	handler = RateLimit(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if nested == nil {
+			// A second call while the first one is still in flight
+			nested, _ = handler(callContext("rl-s3", nil), req)
+		}
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	cr, err := handler(callContext("rl-s3", nil), callRequest("milvus_query", nil))
+	require.NoError(t, err)
+	assert.False(t, cr.IsError)
+	require.NotNil(t, nested)
+	assert.True(t, nested.IsError, "the caller already has a call in flight")
+
+	cr, err = handler(callContext("rl-s3", nil), callRequest("milvus_query", nil))
+	require.NoError(t, err)
+	assert.False(t, cr.IsError, "the slot is released once the call completes")
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/tailabs/mcp-milvus/internal/auth"
+	"github.com/tailabs/mcp-milvus/internal/policy"
+	"github.com/tailabs/mcp-milvus/internal/registry"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTool struct {
+	name   string
+	access registry.Access
+}
+
+func (t *fakeTool) GetTool() mcp.Tool                  { return mcp.NewTool(t.name) }
+func (t *fakeTool) GetHandler() server.ToolHandlerFunc { return nil }
+func (t *fakeTool) GetAccess() registry.Access         { return t.access }
+
+func init() {
+	for name, access := range map[string]registry.Access{
+		"milvus_connector":       registry.AccessRead,
+		"milvus_query":           registry.AccessRead,
+		"milvus_insert_data":     registry.AccessWrite,
+		"milvus_drop_collection": registry.AccessAdmin,
+	} {
+		registry.RegisterTool(&fakeTool{name: name, access: access})
+	}
+}
+
+func TestReadOnlyPolicy(t *testing.T) {
+	configurePolicies(t, policy.Config{Default: policy.PresetReadOnly})
+	analyst := &auth.Principal{Subject: "analyst"}
+
+	tests := []struct {
+		tool     string
+		rejected bool
+	}{
+		{tool: "milvus_connector"},
+		{tool: "milvus_query"},
+		{tool: "milvus_insert_data", rejected: true},
+		{tool: "milvus_drop_collection", rejected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			tool := &stubTool{}
+			cr, err := Authorize(tool.handle)(callContext("readonly-s1", analyst), callRequest(tt.tool, nil))
+			require.NoError(t, err)
+
+			if tt.rejected {
+				assert.True(t, cr.IsError)
+				assert.Contains(t, textOf(t, cr), "is not permitted by policy read-only")
+				assert.Equal(t, 0, tool.calls, "rejected calls never reach the tool")
+				return
+			}
+			assert.False(t, cr.IsError)
+			assert.Equal(t, 1, tool.calls)
+		})
+	}
+
+	tools := []mcp.Tool{mcp.NewTool("milvus_query"), mcp.NewTool("milvus_insert_data"), mcp.NewTool("milvus_drop_collection")}
+	var names []string
+	for _, tool := range FilterTools(callContext("readonly-s1", analyst), tools) {
+		names = append(names, tool.Name)
+	}
+	assert.Equal(t, []string{"milvus_query"}, names, "mutating tools are hidden from tools/list")
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var (
+	// callTimeout bounds tool calls without an entry in toolTimeouts, 0 disables it
+	callTimeout = 60 * time.Second
+	// toolTimeouts overrides callTimeout for single tools, e.g. index builds
+	toolTimeouts = map[string]time.Duration{}
+)
+
+// SetTimeouts configures how long tool calls may take, 0 disables a timeout
+func SetTimeouts(timeout time.Duration, perTool map[string]time.Duration) {
+	callTimeout = timeout
+	toolTimeouts = perTool
+}
+
+func toolTimeout(name string) time.Duration {
+	if timeout, ok := toolTimeouts[name]; ok {
+		return timeout
+	}
+	return callTimeout
+}
+
+// Timeout gives every tool call a deadline, which the Milvus RPCs of the call inherit
+func Timeout(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		timeout := toolTimeout(req.Params.Name)
+		if timeout <= 0 {
+			return next(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		cr, err := next(ctx, req)
+		failed := err != nil || (cr != nil && cr.IsError)
+		if failed && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return mcp.NewToolResultError(fmt.Sprintf("%s timed out after %s", req.Params.Name, timeout)), nil
+		}
+		return cr, err
+	}
+}
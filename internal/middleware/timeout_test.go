@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configureTimeouts sets the call timeouts until the test ends
+func configureTimeouts(t *testing.T, timeout time.Duration, perTool map[string]time.Duration) {
+	t.Helper()
+	prevTimeout, prevPerTool := callTimeout, toolTimeouts
+	SetTimeouts(timeout, perTool)
+	t.Cleanup(func() { SetTimeouts(prevTimeout, prevPerTool) })
+}
+
+// waitForDeadline is a tool handler failing with its context once the call deadline passes
+func waitForDeadline(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	select {
+	case <-ctx.Done():
+		return mcp.NewToolResultError(ctx.Err().Error()), nil
+	case <-time.After(100 * time.Millisecond):
+		return mcp.NewToolResultText("ok"), nil
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	configureTimeouts(t, 10*time.Millisecond, map[string]time.Duration{
+		"milvus_create_index": 0,
+		"milvus_query":        5 * time.Millisecond,
+	})
+
+	tests := []struct {
+		name    string
+		tool    string
+		handler server.ToolHandlerFunc
+		want    string
+		isError bool
+	}{
+		{name: "within the deadline", tool: "milvus_list_collections", handler: (&stubTool{}).handle, want: "ok"},
+		{name: "deadline", tool: "milvus_list_collections", handler: waitForDeadline, want: "milvus_list_collections timed out after 10ms", isError: true},
+		{name: "per-tool deadline", tool: "milvus_query", handler: waitForDeadline, want: "milvus_query timed out after 5ms", isError: true},
+		{name: "disabled for the tool", tool: "milvus_create_index", handler: waitForDeadline, want: "ok"},
+		{name: "other errors", tool: "milvus_query", handler: (&stubTool{result: mcp.NewToolResultError("collection not found")}).handle, want: "collection not found", isError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr, err := Timeout(tt.handler)(context.Background(), callRequest(tt.tool, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.isError, cr.IsError)
+			assert.Equal(t, tt.want, textOf(t, cr))
+		})
+	}
+
+	t.Run("handler errors pass through", func(t *testing.T) {
+		reset := errors.New("connection reset")
+		_, err := Timeout((&stubTool{err: reset}).handle)(context.Background(), callRequest("milvus_query", nil))
+		assert.ErrorIs(t, err, reset)
+	})
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/tailabs/mcp-milvus/internal/auth"
+	"github.com/tailabs/mcp-milvus/internal/tracing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing opens a span for every tool call, continuing the trace passed in the request's _meta.
+// The Milvus RPCs of the call become its child spans.
+func Tracing(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (cr *mcp.CallToolResult, err error) {
+		attrs := []attribute.KeyValue{
+			attribute.String("mcp.tool", req.Params.Name),
+			attribute.String("mcp.session", sessionIDFromContext(ctx)),
+		}
+		args := req.GetArguments()
+		for _, key := range collectionArguments {
+			if name, ok := args[key].(string); ok {
+				attrs = append(attrs, attribute.String("milvus.collection", name))
+				break
+			}
+		}
+		if principal := auth.PrincipalFromContext(ctx); principal != nil {
+			attrs = append(attrs, attribute.String("mcp.principal", principal.Subject))
+		}
+
+		ctx, span := tracing.Tracer().Start(tracing.Extract(ctx, req.Params.Meta), "tool "+req.Params.Name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attrs...),
+		)
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			} else if cr != nil && cr.IsError {
+				span.SetStatus(codes.Error, resultText(cr))
+			}
+			span.End()
+		}()
+
+		return next(ctx, req)
+	}
+}
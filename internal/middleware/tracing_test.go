@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tailabs/mcp-milvus/internal/auth"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// useRecorder installs a tracer provider recording spans in memory for the test
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previous, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func TestTracing(t *testing.T) {
+	tests := []struct {
+		name   string
+		tool   stubTool
+		status codes.Code
+		desc   string
+	}{
+		{name: "success", status: codes.Unset},
+		{name: "tool error", tool: stubTool{result: mcp.NewToolResultError("collection not found")}, status: codes.Error, desc: "collection not found"},
+		{name: "handler error", tool: stubTool{err: errors.New("connection reset")}, status: codes.Error, desc: "connection reset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := useRecorder(t)
+			req := callRequest("milvus_query", map[string]any{"collection_name": "docs"})
+			req.Params.Meta = &mcp.Meta{AdditionalFields: map[string]any{
+				"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			}}
+			_, _ = Tracing(tt.tool.handle)(callContext("trace-s1", &auth.Principal{Subject: "alice"}), req)
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			span := spans[0]
+			assert.Equal(t, "tool milvus_query", span.Name())
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.Parent().TraceID().String(), "the span continues the client's trace")
+			assert.Equal(t, span.SpanContext(), trace.SpanContextFromContext(tt.tool.ctx), "Milvus RPCs of the call become child spans")
+			assert.Subset(t, span.Attributes(), []attribute.KeyValue{
+				attribute.String("mcp.tool", "milvus_query"),
+				attribute.String("mcp.session", "trace-s1"),
+				attribute.String("milvus.collection", "docs"),
+				attribute.String("mcp.principal", "alice"),
+			})
+			assert.Equal(t, tt.status, span.Status().Code)
+			assert.Equal(t, tt.desc, span.Status().Description)
+		})
+	}
+}
+
+// Tracing runs before Auth, so rejected calls are traced as failed
+func TestTracingRecordsRejectedCalls(t *testing.T) {
+	recorder := useRecorder(t)
+	tool := &stubTool{}
+
+	_, err := chain(tool.handle)(callContext("", nil), callRequest("milvus_query", nil))
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "must provide an available session id", spans[0].Status().Description)
+}
@@ -0,0 +1,125 @@
+package policy
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/tailabs/mcp-milvus/internal/auth"
+)
+
+// Config maps principals, roles and connection profiles onto named policies
+type Config struct {
+	// Policies holds the custom policies by name, the presets are always available
+	Policies map[string]*Policy
+	// Principals, Roles and Profiles map a principal subject, a principal role or a
+	// connection profile onto a policy name
+	Principals map[string]string
+	Roles      map[string]string
+	Profiles   map[string]string
+	// Default applies when nothing else matches, empty means unrestricted
+	Default string
+}
+
+// Engine resolves the policy that applies to a caller
+type Engine struct {
+	policies      map[string]*Policy
+	principals    map[string]string
+	roles         map[string]string
+	profiles      map[string]string
+	defaultPolicy string
+}
+
+// NewEngine validates the config and creates a policy engine
+func NewEngine(cfg Config) (*Engine, error) {
+	e := &Engine{
+		policies:      make(map[string]*Policy, len(cfg.Policies)+3),
+		principals:    copyMap(cfg.Principals),
+		roles:         copyMap(cfg.Roles),
+		profiles:      copyMap(cfg.Profiles),
+		defaultPolicy: cfg.Default,
+	}
+
+	for _, name := range PresetNames() {
+		e.policies[name], _ = Preset(name)
+	}
+	for name, p := range cfg.Policies {
+		if _, ok := Preset(name); ok {
+			return nil, fmt.Errorf("policy name %s is reserved for a built-in policy", name)
+		}
+		copied := *p
+		copied.Name = name
+		if err := copied.Validate(); err != nil {
+			return nil, err
+		}
+		e.policies[name] = &copied
+	}
+
+	for kind, bindings := range map[string]map[string]string{"principal": e.principals, "role": e.roles, "profile": e.profiles} {
+		for subject, name := range bindings {
+			if _, ok := e.policies[name]; !ok {
+				return nil, fmt.Errorf("%s %s references unknown policy %s", kind, subject, name)
+			}
+		}
+	}
+	if e.defaultPolicy != "" {
+		if _, ok := e.policies[e.defaultPolicy]; !ok {
+			return nil, fmt.Errorf("default policy not found: %s", e.defaultPolicy)
+		}
+	}
+	return e, nil
+}
+
+// Resolve returns the policy for the caller, checking the principal subject, then
+// its roles in order, then the connection profile, then the default
+// A nil result means the caller is unrestricted
+func (e *Engine) Resolve(principal *auth.Principal, profile string) *Policy {
+	if principal != nil {
+		if name, ok := e.principals[principal.Subject]; ok {
+			return e.policies[name]
+		}
+		for _, role := range principal.Roles {
+			if name, ok := e.roles[role]; ok {
+				return e.policies[name]
+			}
+		}
+	}
+	if profile != "" {
+		if name, ok := e.profiles[profile]; ok {
+			return e.policies[name]
+		}
+	}
+	if e.defaultPolicy != "" {
+		return e.policies[e.defaultPolicy]
+	}
+	return nil
+}
+
+var (
+	engineMu sync.RWMutex
+	engine   *Engine
+)
+
+// SetEngine installs the global policy engine, nil disables authorization
+func SetEngine(e *Engine) {
+	engineMu.Lock()
+	defer engineMu.Unlock()
+	engine = e
+}
+
+// Resolve returns the policy for the caller from the global engine
+func Resolve(principal *auth.Principal, profile string) *Policy {
+	engineMu.RLock()
+	defer engineMu.RUnlock()
+	if engine == nil {
+		return nil
+	}
+	return engine.Resolve(principal, profile)
+}
+
+func copyMap(m map[string]string) map[string]string {
+	copied := make(map[string]string, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
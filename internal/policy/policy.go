@@ -0,0 +1,115 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/tailabs/mcp-milvus/internal/registry"
+)
+
+// Built-in policies, usable by name without defining them
+const (
+	PresetReadOnly  = "read-only"
+	PresetReadWrite = "read-write"
+	PresetAdmin     = "admin"
+)
+
+// defaultDatabase is the database Milvus uses when none is selected
+const defaultDatabase = "default"
+
+// Policy restricts which tools a caller may use and which databases and collections
+// those tools may touch. Every list holds glob patterns as understood by path.Match.
+// A nil *Policy allows everything.
+type Policy struct {
+	Name string
+	// AllowTools lists the permitted tools, empty permits every tool not denied
+	AllowTools []string
+	DenyTools  []string
+	// Databases and Collections list the permitted names, empty permits all
+	Databases   []string
+	Collections []string
+}
+
+// Preset returns a copy of the named built-in policy
+// The read-only and read-write presets follow the access classification of the registered tools
+func Preset(name string) (*Policy, bool) {
+	switch name {
+	case PresetReadOnly:
+		return &Policy{Name: name, AllowTools: registry.ToolNames(registry.AccessRead)}, true
+	case PresetReadWrite:
+		return &Policy{Name: name, AllowTools: registry.ToolNames(registry.AccessWrite)}, true
+	case PresetAdmin:
+		return &Policy{Name: name, AllowTools: []string{"*"}}, true
+	}
+	return nil, false
+}
+
+// PresetNames returns the names of the built-in policies
+func PresetNames() []string {
+	return []string{PresetReadOnly, PresetReadWrite, PresetAdmin}
+}
+
+// Validate checks that every pattern is well formed
+func (p *Policy) Validate() error {
+	for _, patterns := range [][]string{p.AllowTools, p.DenyTools, p.Databases, p.Collections} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("policy %s: invalid pattern %q", p.Name, pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// AllowsTool reports whether the tool may be called
+func (p *Policy) AllowsTool(name string) bool {
+	if p == nil {
+		return true
+	}
+	if matchAny(p.DenyTools, name) {
+		return false
+	}
+	return len(p.AllowTools) == 0 || matchAny(p.AllowTools, name)
+}
+
+// AllowsDatabase reports whether the database may be used, "" is the default database
+func (p *Policy) AllowsDatabase(name string) bool {
+	if p == nil || len(p.Databases) == 0 {
+		return true
+	}
+	if name == "" {
+		name = defaultDatabase
+	}
+	return matchAny(p.Databases, name)
+}
+
+// AllowsCollection reports whether the collection may be used
+func (p *Policy) AllowsCollection(name string) bool {
+	if p == nil || len(p.Collections) == 0 {
+		return true
+	}
+	return matchAny(p.Collections, name)
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type policyKey struct{}
+
+// WithPolicy returns a copy of ctx carrying the policy that applies to the tool call
+func WithPolicy(ctx context.Context, p *Policy) context.Context {
+	return context.WithValue(ctx, policyKey{}, p)
+}
+
+// FromContext returns the policy bound to ctx, nil means unrestricted
+func FromContext(ctx context.Context) *Policy {
+	p, _ := ctx.Value(policyKey{}).(*Policy)
+	return p
+}
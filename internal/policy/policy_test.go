@@ -0,0 +1,164 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tailabs/mcp-milvus/internal/auth"
+	"github.com/tailabs/mcp-milvus/internal/registry"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTool struct {
+	name   string
+	access registry.Access
+}
+
+func (t *fakeTool) GetTool() mcp.Tool                  { return mcp.NewTool(t.name) }
+func (t *fakeTool) GetHandler() server.ToolHandlerFunc { return nil }
+func (t *fakeTool) GetAccess() registry.Access         { return t.access }
+
+func init() {
+	for name, access := range map[string]registry.Access{
+		"milvus_connector":           registry.AccessRead,
+		"milvus_list_collections":    registry.AccessRead,
+		"milvus_get_collection_info": registry.AccessRead,
+		"milvus_query":               registry.AccessRead,
+		"milvus_vector_search":       registry.AccessRead,
+		"milvus_insert_data":         registry.AccessWrite,
+		"milvus_delete_entities":     registry.AccessWrite,
+		"milvus_load_collection":     registry.AccessWrite,
+		"milvus_create_collection":   registry.AccessAdmin,
+		"milvus_create_database":     registry.AccessAdmin,
+		"milvus_drop_collection":     registry.AccessAdmin,
+		"milvus_drop_index":          registry.AccessAdmin,
+	} {
+		registry.RegisterTool(&fakeTool{name: name, access: access})
+	}
+}
+
+func TestPresets(t *testing.T) {
+	tests := []struct {
+		preset  string
+		allowed []string
+		denied  []string
+	}{
+		{
+			preset:  PresetReadOnly,
+			allowed: []string{"milvus_connector", "milvus_list_collections", "milvus_get_collection_info", "milvus_query", "milvus_vector_search"},
+			denied:  []string{"milvus_insert_data", "milvus_drop_collection", "milvus_create_database"},
+		},
+		{
+			preset:  PresetReadWrite,
+			allowed: []string{"milvus_query", "milvus_insert_data", "milvus_delete_entities", "milvus_load_collection"},
+			denied:  []string{"milvus_drop_collection", "milvus_create_collection", "milvus_drop_index"},
+		},
+		{
+			preset:  PresetAdmin,
+			allowed: []string{"milvus_drop_collection", "milvus_create_database"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			p, ok := Preset(tt.preset)
+			require.True(t, ok)
+			for _, tool := range tt.allowed {
+				assert.True(t, p.AllowsTool(tool), tool)
+			}
+			for _, tool := range tt.denied {
+				assert.False(t, p.AllowsTool(tool), tool)
+			}
+		})
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	p := &Policy{
+		Name:        "analyst",
+		DenyTools:   []string{"milvus_drop_*"},
+		Databases:   []string{"default", "analytics"},
+		Collections: []string{"reports_*"},
+	}
+
+	assert.True(t, p.AllowsTool("milvus_insert_data"), "empty allow list permits everything not denied")
+	assert.False(t, p.AllowsTool("milvus_drop_index"))
+	assert.True(t, p.AllowsDatabase(""), "empty database name is the default database")
+	assert.True(t, p.AllowsDatabase("analytics"))
+	assert.False(t, p.AllowsDatabase("billing"))
+	assert.True(t, p.AllowsCollection("reports_2024"))
+	assert.False(t, p.AllowsCollection("users"))
+
+	var unrestricted *Policy
+	assert.True(t, unrestricted.AllowsTool("milvus_drop_collection"))
+	assert.True(t, unrestricted.AllowsDatabase("billing"))
+	assert.True(t, unrestricted.AllowsCollection("users"))
+
+	assert.Error(t, (&Policy{Name: "bad", Collections: []string{"["}}).Validate())
+}
+
+func TestEngineResolve(t *testing.T) {
+	e, err := NewEngine(Config{
+		Policies: map[string]*Policy{
+			"analyst": {Collections: []string{"reports_*"}},
+		},
+		Principals: map[string]string{"alice": PresetAdmin},
+		Roles:      map[string]string{"reader": "analyst", "writer": PresetReadWrite},
+		Profiles:   map[string]string{"prod": PresetReadOnly},
+		Default:    PresetReadOnly,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		profile   string
+		want      string
+	}{
+		{"principal", &auth.Principal{Subject: "alice", Roles: []string{"reader"}}, "prod", PresetAdmin},
+		{"first matching role", &auth.Principal{Subject: "bob", Roles: []string{"guest", "writer", "reader"}}, "prod", PresetReadWrite},
+		{"profile", &auth.Principal{Subject: "bob"}, "prod", PresetReadOnly},
+		{"default", nil, "", PresetReadOnly},
+		{"custom policy", &auth.Principal{Subject: "carol", Roles: []string{"reader"}}, "", "analyst"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := e.Resolve(tt.principal, tt.profile)
+			require.NotNil(t, p)
+			assert.Equal(t, tt.want, p.Name)
+		})
+	}
+
+	unrestricted, err := NewEngine(Config{})
+	require.NoError(t, err)
+	assert.Nil(t, unrestricted.Resolve(&auth.Principal{Subject: "alice"}, "prod"))
+}
+
+func TestNewEngineErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"reserved name", Config{Policies: map[string]*Policy{PresetAdmin: {}}}},
+		{"unknown principal policy", Config{Principals: map[string]string{"alice": "missing"}}},
+		{"unknown default", Config{Default: "missing"}},
+		{"invalid pattern", Config{Policies: map[string]*Policy{"bad": {AllowTools: []string{"["}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewEngine(tt.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	assert.Nil(t, FromContext(context.Background()))
+
+	p, _ := Preset(PresetReadOnly)
+	assert.Equal(t, p, FromContext(WithPolicy(context.Background(), p)))
+}
@@ -0,0 +1,165 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// idleTimeout is how long the buckets of a caller without calls are kept
+const idleTimeout = 10 * time.Minute
+
+// Options configures the limits applied to every caller
+type Options struct {
+	// CallsPerSecond refills the bucket of all tool calls, 0 disables it
+	CallsPerSecond float64
+	Burst          int
+
+	// ExpensiveCallsPerSecond refills a separate bucket that ExpensiveTools also draw from
+	ExpensiveCallsPerSecond float64
+	ExpensiveBurst          int
+	ExpensiveTools          []string
+
+	// MaxConcurrent limits the calls of a caller in flight at the same time, 0 disables it
+	MaxConcurrent int
+}
+
+// Error reports a call rejected by a limit and when the caller may try again
+type Error struct {
+	Key        string
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("rate limit exceeded for %s: %s, retry after %s", e.Key, e.Reason, e.RetryAfter.Round(time.Millisecond))
+}
+
+// Limiter enforces token buckets and a concurrency limit per caller
+type Limiter struct {
+	mu        sync.Mutex
+	opts      Options
+	expensive map[string]bool
+	callers   map[string]*caller
+	lastSweep time.Time
+}
+
+type caller struct {
+	calls     *rate.Limiter
+	expensive *rate.Limiter
+	inFlight  int
+	lastUsed  time.Time
+}
+
+// NewLimiter creates a limiter, a zero Options value allows everything
+func NewLimiter(opts Options) *Limiter {
+	expensive := make(map[string]bool, len(opts.ExpensiveTools))
+	for _, tool := range opts.ExpensiveTools {
+		expensive[tool] = true
+	}
+	return &Limiter{
+		opts:      opts,
+		expensive: expensive,
+		callers:   make(map[string]*caller),
+		lastSweep: time.Now(),
+	}
+}
+
+var limiter = NewLimiter(Options{})
+
+// Configure replaces the global limiter
+func Configure(opts Options) {
+	limiter = NewLimiter(opts)
+}
+
+// GetLimiter returns the global limiter
+func GetLimiter() *Limiter {
+	return limiter
+}
+
+// Enabled reports whether any limit is configured
+func (l *Limiter) Enabled() bool {
+	return l.opts.CallsPerSecond > 0 || l.opts.ExpensiveCallsPerSecond > 0 || l.opts.MaxConcurrent > 0
+}
+
+// Acquire admits a call of tool by the caller identified by key. The returned
+// release must be called once the call completes.
+func (l *Limiter) Acquire(key, tool string) (release func(), err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+	c := l.caller(key, now)
+
+	if l.opts.MaxConcurrent > 0 && c.inFlight >= l.opts.MaxConcurrent {
+		// A slot frees up when one of the running calls completes, there is no better estimate
+		return nil, &Error{Key: key, Reason: fmt.Sprintf("%d calls already in flight", c.inFlight), RetryAfter: time.Second}
+	}
+
+	var reservations []*rate.Reservation
+	cancel := func() {
+		for _, r := range reservations {
+			r.CancelAt(now)
+		}
+	}
+	if c.calls != nil {
+		r := c.calls.ReserveN(now, 1)
+		if delay := r.DelayFrom(now); !r.OK() || delay > 0 {
+			r.CancelAt(now)
+			return nil, &Error{Key: key, Reason: fmt.Sprintf("more than %g calls per second", l.opts.CallsPerSecond), RetryAfter: delay}
+		}
+		reservations = append(reservations, r)
+	}
+	if c.expensive != nil && l.expensive[tool] {
+		r := c.expensive.ReserveN(now, 1)
+		if delay := r.DelayFrom(now); !r.OK() || delay > 0 {
+			r.CancelAt(now)
+			cancel()
+			return nil, &Error{Key: key, Reason: fmt.Sprintf("more than %g %s calls per second", l.opts.ExpensiveCallsPerSecond, tool), RetryAfter: delay}
+		}
+	}
+
+	c.inFlight++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			c.inFlight--
+			c.lastUsed = time.Now()
+		})
+	}, nil
+}
+
+// caller returns the buckets of key, creating them on first use
+func (l *Limiter) caller(key string, now time.Time) *caller {
+	c, ok := l.callers[key]
+	if !ok {
+		c = &caller{}
+		if l.opts.CallsPerSecond > 0 {
+			c.calls = rate.NewLimiter(rate.Limit(l.opts.CallsPerSecond), max(l.opts.Burst, 1))
+		}
+		if l.opts.ExpensiveCallsPerSecond > 0 {
+			c.expensive = rate.NewLimiter(rate.Limit(l.opts.ExpensiveCallsPerSecond), max(l.opts.ExpensiveBurst, 1))
+		}
+		l.callers[key] = c
+	}
+	c.lastUsed = now
+	return c
+}
+
+// sweep forgets callers idle for longer than idleTimeout, whose buckets have refilled by then
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, c := range l.callers {
+		if c.inFlight == 0 && now.Sub(c.lastUsed) > idleTimeout {
+			delete(l.callers, key)
+		}
+	}
+}
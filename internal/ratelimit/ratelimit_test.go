@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallBudget(t *testing.T) {
+	l := NewLimiter(Options{CallsPerSecond: 1, Burst: 2})
+
+	for i := 0; i < 2; i++ {
+		release, err := l.Acquire("principal alice", "milvus_query")
+		require.NoError(t, err)
+		release()
+	}
+
+	_, err := l.Acquire("principal alice", "milvus_query")
+	var limitErr *Error
+	require.True(t, errors.As(err, &limitErr))
+	assert.Positive(t, limitErr.RetryAfter)
+	assert.Contains(t, err.Error(), "retry after")
+
+	_, err = l.Acquire("principal bob", "milvus_query")
+	assert.NoError(t, err, "every caller has its own bucket")
+}
+
+func TestExpensiveBudget(t *testing.T) {
+	l := NewLimiter(Options{
+		CallsPerSecond:          100,
+		Burst:                   100,
+		ExpensiveCallsPerSecond: 1,
+		ExpensiveBurst:          1,
+		ExpensiveTools:          []string{"milvus_vector_search"},
+	})
+
+	_, err := l.Acquire("session s1", "milvus_vector_search")
+	require.NoError(t, err)
+	_, err = l.Acquire("session s1", "milvus_vector_search")
+	assert.Error(t, err)
+	_, err = l.Acquire("session s1", "milvus_query")
+	assert.NoError(t, err, "cheap tools keep their own budget")
+}
+
+func TestMaxConcurrent(t *testing.T) {
+	l := NewLimiter(Options{MaxConcurrent: 1})
+
+	release, err := l.Acquire("session s1", "milvus_insert_data")
+	require.NoError(t, err)
+	_, err = l.Acquire("session s1", "milvus_query")
+	assert.Error(t, err)
+
+	release()
+	release() // releasing twice frees a single slot
+	release2, err := l.Acquire("session s1", "milvus_query")
+	require.NoError(t, err)
+	_, err = l.Acquire("session s1", "milvus_query")
+	assert.Error(t, err)
+	release2()
+}
+
+func TestDisabled(t *testing.T) {
+	l := NewLimiter(Options{})
+	assert.False(t, l.Enabled())
+	for i := 0; i < 100; i++ {
+		_, err := l.Acquire("session s1", "milvus_vector_search")
+		require.NoError(t, err)
+	}
+}
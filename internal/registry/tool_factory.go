@@ -1,13 +1,42 @@
 package registry
 
 import (
+	"sort"
+
+	"github.com/tailabs/mcp-milvus/internal/confirm"
+
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// Access classifies what a tool may change in Milvus
+type Access int
+
+const (
+	// AccessRead tools only read data or metadata, or change session state
+	AccessRead Access = iota
+	// AccessWrite tools modify the data inside existing collections
+	AccessWrite
+	// AccessAdmin tools create, drop or rename databases, collections and indexes
+	AccessAdmin
+)
+
+func (a Access) String() string {
+	switch a {
+	case AccessRead:
+		return "read"
+	case AccessWrite:
+		return "write"
+	case AccessAdmin:
+		return "admin"
+	}
+	return "unknown"
+}
+
 type ToolRegistrar interface {
 	GetTool() mcp.Tool
 	GetHandler() server.ToolHandlerFunc
+	GetAccess() Access
 }
 
 var globalToolRegistry = make([]ToolRegistrar, 0)
@@ -17,7 +46,43 @@ func RegisterTool(tool ToolRegistrar) {
 }
 
 func RegisterAllTools(s *server.MCPServer) {
+	RegisterTools(s, AccessAdmin)
+}
+
+// RegisterTools registers the tools whose access does not exceed maxAccess,
+// e.g. AccessRead for a read-only server
+func RegisterTools(s *server.MCPServer, maxAccess Access) {
+	for _, tool := range globalToolRegistry {
+		access := tool.GetAccess()
+		if access > maxAccess {
+			continue
+		}
+
+		// Let clients know which tools are safe to call without confirmation
+		t := tool.GetTool()
+		readOnly := access == AccessRead
+		destructive := !readOnly
+		t.Annotations.ReadOnlyHint = &readOnly
+		t.Annotations.DestructiveHint = &destructive
+
+		// Destructive tools with a preview only execute once the preview is confirmed
+		handler := tool.GetHandler()
+		if previewer, ok := tool.(confirm.Previewer); ok && confirm.GetManager().Enabled() {
+			t = confirm.GetManager().WithConfirmation(t)
+			handler = confirm.GetManager().Wrap(previewer, handler)
+		}
+		s.AddTool(t, handler)
+	}
+}
+
+// ToolNames returns the sorted names of the registered tools whose access does not exceed maxAccess
+func ToolNames(maxAccess Access) []string {
+	names := make([]string, 0, len(globalToolRegistry))
 	for _, tool := range globalToolRegistry {
-		s.AddTool(tool.GetTool(), tool.GetHandler())
+		if tool.GetAccess() <= maxAccess {
+			names = append(names, tool.GetTool().Name)
+		}
 	}
+	sort.Strings(names)
+	return names
 }
@@ -0,0 +1,66 @@
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTool struct {
+	name   string
+	access Access
+}
+
+func (t *fakeTool) GetTool() mcp.Tool { return mcp.NewTool(t.name) }
+
+func (t *fakeTool) GetHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(t.name), nil
+	}
+}
+
+func (t *fakeTool) GetAccess() Access { return t.access }
+
+func listTools(t *testing.T, s *server.MCPServer) map[string]mcp.Tool {
+	t.Helper()
+	resp := s.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	result, ok := resp.(mcp.JSONRPCResponse).Result.(mcp.ListToolsResult)
+	require.True(t, ok)
+
+	tools := make(map[string]mcp.Tool, len(result.Tools))
+	for _, tool := range result.Tools {
+		tools[tool.Name] = tool
+	}
+	return tools
+}
+
+func TestRegisterTools(t *testing.T) {
+	saved := globalToolRegistry
+	defer func() { globalToolRegistry = saved }()
+
+	globalToolRegistry = nil
+	RegisterTool(&fakeTool{name: "query", access: AccessRead})
+	RegisterTool(&fakeTool{name: "insert", access: AccessWrite})
+	RegisterTool(&fakeTool{name: "drop", access: AccessAdmin})
+
+	assert.Equal(t, []string{"query"}, ToolNames(AccessRead))
+	assert.Equal(t, []string{"insert", "query"}, ToolNames(AccessWrite))
+
+	readOnly := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(true))
+	RegisterTools(readOnly, AccessRead)
+	tools := listTools(t, readOnly)
+	require.Len(t, tools, 1)
+	assert.True(t, *tools["query"].Annotations.ReadOnlyHint)
+	assert.False(t, *tools["query"].Annotations.DestructiveHint)
+
+	all := server.NewMCPServer("test", "0.0.0", server.WithToolCapabilities(true))
+	RegisterAllTools(all)
+	tools = listTools(t, all)
+	assert.Len(t, tools, 3)
+	assert.False(t, *tools["drop"].Annotations.ReadOnlyHint)
+	assert.True(t, *tools["drop"].Annotations.DestructiveHint)
+}
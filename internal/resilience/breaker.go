@@ -0,0 +1,112 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrCircuitOpen is returned without calling Milvus while its address keeps failing
+var ErrCircuitOpen = errors.New("milvus circuit breaker open")
+
+// Breaker stops calls to a Milvus address after consecutive outages, so agents fail
+// fast instead of waiting for timeouts. After OpenTimeout a single probe call is let
+// through, closing the circuit again if the server answers.
+type Breaker struct {
+	mu          sync.Mutex
+	address     string
+	threshold   int
+	openTimeout time.Duration
+
+	failures int
+	// openedAt is zero while the circuit is closed
+	openedAt time.Time
+	probing  bool
+}
+
+func newBreaker(address string, threshold int, openTimeout time.Duration) *Breaker {
+	return &Breaker{
+		address:     address,
+		threshold:   threshold,
+		openTimeout: openTimeout,
+	}
+}
+
+// Allow fails with ErrCircuitOpen while calls to the address must not be made
+// It reports whether the call is the probe of an open circuit, which Record needs to know
+func (b *Breaker) Allow() (probe bool, err error) {
+	if b.threshold <= 0 {
+		return false, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openedAt.IsZero() {
+		return false, nil
+	}
+	retryAfter := b.openTimeout - time.Since(b.openedAt)
+	if retryAfter > 0 || b.probing {
+		if retryAfter < time.Second {
+			retryAfter = time.Second
+		}
+		return false, fmt.Errorf("%w for %s, retry after %s", ErrCircuitOpen, b.address, retryAfter.Round(time.Second))
+	}
+	b.probing = true
+	return true, nil
+}
+
+// Record updates the circuit with the outcome of a call that Allow let through
+func (b *Breaker) Record(ctx context.Context, probe bool, err error) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err != nil && ctx.Err() != nil {
+		// The caller gave up, which says nothing about the server, a canceled
+		// probe leaves the probing to the next call
+		if probe {
+			b.probing = false
+		}
+		return
+	}
+
+	if !isOutage(ctx, err) {
+		if !b.openedAt.IsZero() && !probe {
+			// A call started before the circuit opened, keep waiting for the probe
+			return
+		}
+		if !b.openedAt.IsZero() {
+			logrus.WithField("address", b.address).Info("Milvus circuit breaker closed")
+		}
+		b.failures = 0
+		b.openedAt = time.Time{}
+		b.probing = false
+		return
+	}
+
+	b.failures++
+	if probe || (b.openedAt.IsZero() && b.failures >= b.threshold) {
+		b.openedAt = time.Now()
+		b.probing = false
+		logrus.WithFields(logrus.Fields{
+			"address":  b.address,
+			"failures": b.failures,
+			"error":    err,
+		}).Warn("Milvus circuit breaker opened")
+	}
+}
+
+// Open reports whether the circuit currently rejects calls
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.openedAt.IsZero()
+}
@@ -0,0 +1,59 @@
+package resilience
+
+import (
+	"context"
+	"time"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"google.golang.org/grpc"
+)
+
+// UnaryClientInterceptor retries retryable Milvus RPCs with backoff and guards the
+// address with its circuit breaker. It is installed on every Milvus client, so tools
+// get retries and fail-fast without handling them themselves. Writes are only retried
+// when Milvus rejected them before executing them, see retryable.
+func UnaryClientInterceptor(address string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		options := getOptions()
+		breaker := BreakerFor(address)
+
+		for attempt := 1; ; attempt++ {
+			probe, err := breaker.Allow()
+			if err != nil {
+				return err
+			}
+
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			// Milvus reports most failures in the reply status, the client checks it itself
+			callErr := err
+			if callErr == nil {
+				callErr = statusError(reply)
+			}
+			breaker.Record(ctx, probe, callErr)
+
+			if callErr == nil || attempt >= options.MaxAttempts || !retryable(method, callErr) {
+				return err
+			}
+
+			timer := time.NewTimer(options.backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// statusError converts the status of a Milvus reply into an error
+func statusError(reply any) error {
+	switch r := reply.(type) {
+	case *commonpb.Status:
+		return merr.Error(r)
+	case interface{ GetStatus() *commonpb.Status }:
+		return merr.Error(r.GetStatus())
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Options configures retries and circuit breaking of Milvus RPCs
+type Options struct {
+	// MaxAttempts is how often a retryable RPC is tried, 1 disables retries
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// FailureThreshold consecutive failures open the circuit of an address, 0 disables the breaker
+	FailureThreshold int
+	// OpenTimeout is how long an open circuit rejects calls before letting a probe through
+	OpenTimeout time.Duration
+}
+
+// DefaultOptions returns the default retry and circuit breaker options
+func DefaultOptions() Options {
+	return Options{
+		MaxAttempts:      3,
+		InitialBackoff:   200 * time.Millisecond,
+		MaxBackoff:       2 * time.Second,
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+	}
+}
+
+var (
+	mu       sync.Mutex
+	options  = DefaultOptions()
+	breakers = make(map[string]*Breaker)
+)
+
+// Configure sets the options of every Milvus client and resets the circuit breakers
+func Configure(opts Options) {
+	mu.Lock()
+	defer mu.Unlock()
+	options = opts
+	breakers = make(map[string]*Breaker)
+}
+
+func getOptions() Options {
+	mu.Lock()
+	defer mu.Unlock()
+	return options
+}
+
+// BreakerFor returns the circuit breaker shared by all clients of a Milvus address
+func BreakerFor(address string) *Breaker {
+	mu.Lock()
+	defer mu.Unlock()
+
+	breaker, ok := breakers[address]
+	if !ok {
+		breaker = newBreaker(address, options.FailureThreshold, options.OpenTimeout)
+		breakers[address] = breaker
+	}
+	return breaker
+}
+
+// Retryable reports whether a failed Milvus call may succeed when tried again,
+// e.g. because the server is not ready yet or rate limited the call
+func Retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if merr.IsRetryableErr(err) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+// idempotentMethods are the Milvus RPCs that leave the same state when repeated, so
+// they are retried even if a failed attempt may have reached the server
+var idempotentMethods = map[string]bool{
+	"Connect":                 true,
+	"CheckHealth":             true,
+	"GetVersion":              true,
+	"ListDatabases":           true,
+	"DescribeDatabase":        true,
+	"ShowCollections":         true,
+	"HasCollection":           true,
+	"DescribeCollection":      true,
+	"GetCollectionStatistics": true,
+	"ShowPartitions":          true,
+	"HasPartition":            true,
+	"DescribeIndex":           true,
+	"GetIndexState":           true,
+	"GetIndexBuildProgress":   true,
+	"GetLoadState":            true,
+	"GetLoadingProgress":      true,
+	"LoadCollection":          true,
+	"ReleaseCollection":       true,
+	"Query":                   true,
+	"Search":                  true,
+	"HybridSearch":            true,
+}
+
+// retryable reports whether the failed RPC may be tried again. Other than reads and
+// idempotent calls, RPCs such as Insert or DropCollection are only retried when
+// Milvus guarantees it did not execute them.
+func retryable(method string, err error) bool {
+	if !Retryable(err) {
+		return false
+	}
+	if idempotentMethods[method[strings.LastIndex(method, "/")+1:]] {
+		return true
+	}
+	return notExecuted(err)
+}
+
+// notExecuted reports whether err means Milvus rejected the call before executing it
+func notExecuted(err error) bool {
+	return errors.Is(err, merr.ErrServiceNotReady) ||
+		errors.Is(err, merr.ErrServiceTooManyRequests) ||
+		errors.Is(err, merr.ErrServiceRateLimit)
+}
+
+// isOutage reports whether err means the Milvus server could not serve the call at all,
+// as opposed to rejecting it. Only outages count towards opening the circuit.
+func isOutage(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		// The caller gave up, which says nothing about the server
+		return false
+	}
+	if errors.Is(err, merr.ErrServiceNotReady) || errors.Is(err, merr.ErrServiceUnavailable) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// backoff returns the wait before the given retry, doubling from InitialBackoff up to MaxBackoff
+func (o Options) backoff(retry int) time.Duration {
+	wait := o.InitialBackoff
+	for i := 1; i < retry && wait < o.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if o.MaxBackoff > 0 && wait > o.MaxBackoff {
+		wait = o.MaxBackoff
+	}
+	return wait
+}
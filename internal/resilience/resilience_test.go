@@ -0,0 +1,162 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func useOptions(t *testing.T, opts Options) {
+	t.Helper()
+	Configure(opts)
+	t.Cleanup(func() { Configure(DefaultOptions()) })
+}
+
+// failingInvoker fails with the given errors in turn, then succeeds
+func failingInvoker(calls *int, errs ...error) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		*calls++
+		if *calls <= len(errs) {
+			return errs[*calls-1]
+		}
+		return nil
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	assert.True(t, Retryable(status.Error(codes.Unavailable, "connection refused")))
+	assert.True(t, Retryable(merr.ErrServiceNotReady))
+	assert.False(t, Retryable(status.Error(codes.InvalidArgument, "bad filter")))
+	assert.False(t, Retryable(merr.ErrCollectionNotFound))
+	assert.False(t, Retryable(context.DeadlineExceeded))
+	assert.False(t, Retryable(nil))
+}
+
+func TestInterceptorRetries(t *testing.T) {
+	useOptions(t, Options{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+	interceptor := UnaryClientInterceptor("localhost:19530")
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+
+	var calls int
+	err := interceptor(context.Background(), "/milvus.proto.milvus.MilvusService/Search", nil, nil, nil, failingInvoker(&calls, unavailable))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	calls = 0
+	err = interceptor(context.Background(), "/Search", nil, nil, nil, failingInvoker(&calls, unavailable, unavailable, unavailable))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 3, calls, "gives up after MaxAttempts")
+
+	calls = 0
+	err = interceptor(context.Background(), "/milvus.proto.milvus.MilvusService/Insert", nil, nil, nil, failingInvoker(&calls, unavailable))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 1, calls, "writes that may have reached the server are not retried")
+
+	calls = 0
+	err = interceptor(context.Background(), "/milvus.proto.milvus.MilvusService/Insert", nil, nil, nil, failingInvoker(&calls, merr.ErrServiceRateLimit))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls, "writes rejected before executing are retried")
+
+	calls = 0
+	invalid := status.Error(codes.InvalidArgument, "bad filter")
+	err = interceptor(context.Background(), "/Query", nil, nil, nil, failingInvoker(&calls, invalid))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, 1, calls, "non-retryable errors are returned at once")
+}
+
+func TestInterceptorRetriesReplyStatus(t *testing.T) {
+	useOptions(t, Options{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+	interceptor := UnaryClientInterceptor("localhost:19530")
+
+	var calls int
+	reply := &commonpb.Status{}
+	invoker := func(ctx context.Context, method string, req, r any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+		if calls == 1 {
+			*reply = *merr.Status(merr.ErrServiceNotReady)
+		} else {
+			*reply = commonpb.Status{}
+		}
+		return nil
+	}
+	require.NoError(t, interceptor(context.Background(), "/CreateIndex", nil, reply, nil, invoker))
+	assert.Equal(t, 2, calls)
+}
+
+func TestBreaker(t *testing.T) {
+	ctx := context.Background()
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+	b := newBreaker("localhost:19530", 2, 50*time.Millisecond)
+
+	probe, err := b.Allow()
+	require.NoError(t, err)
+	assert.False(t, probe)
+	b.Record(ctx, false, unavailable)
+	b.Record(ctx, false, status.Error(codes.NotFound, "collection not found"))
+	b.Record(ctx, false, unavailable)
+	assert.False(t, b.Open(), "rejected calls reset the failure count")
+
+	b.Record(ctx, false, unavailable)
+	assert.True(t, b.Open())
+	_, err = b.Allow()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	// After the open timeout a single probe is let through
+	time.Sleep(60 * time.Millisecond)
+	probe, err = b.Allow()
+	require.NoError(t, err)
+	assert.True(t, probe)
+	_, err = b.Allow()
+	assert.ErrorIs(t, err, ErrCircuitOpen, "only one probe at a time")
+	b.Record(ctx, false, nil)
+	assert.True(t, b.Open(), "calls started before the circuit opened do not close it")
+	b.Record(ctx, true, unavailable)
+	_, err = b.Allow()
+	assert.ErrorIs(t, err, ErrCircuitOpen, "a failed probe reopens the circuit")
+
+	time.Sleep(60 * time.Millisecond)
+	probe, err = b.Allow()
+	require.NoError(t, err)
+	b.Record(ctx, probe, nil)
+	assert.False(t, b.Open())
+	_, err = b.Allow()
+	assert.NoError(t, err)
+}
+
+func TestBreakerIgnoresCanceledCalls(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	b := newBreaker("localhost:19530", 1, 10*time.Millisecond)
+
+	b.Record(canceled, false, errors.New("context canceled"))
+	assert.False(t, b.Open())
+
+	b.Record(context.Background(), false, status.Error(codes.Unavailable, "connection refused"))
+	time.Sleep(20 * time.Millisecond)
+	probe, err := b.Allow()
+	require.NoError(t, err)
+	b.Record(canceled, probe, errors.New("context canceled"))
+	assert.True(t, b.Open(), "a canceled probe does not close the circuit")
+
+	probe, err = b.Allow()
+	require.NoError(t, err, "the next call probes instead")
+	assert.True(t, probe)
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	b := newBreaker("localhost:19530", 0, time.Minute)
+	for i := 0; i < 10; i++ {
+		b.Record(context.Background(), false, status.Error(codes.Unavailable, "connection refused"))
+	}
+	_, err := b.Allow()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,42 @@
+package session
+
+import "context"
+
+type callStateKey struct{}
+
+// callState carries the session state of one tool call, so the middlewares and the
+// tool share a single load instead of each asking the session manager again
+type callState struct {
+	state *SessionState
+}
+
+// WithCallState prepares ctx to carry the session state of a tool call
+func WithCallState(ctx context.Context) context.Context {
+	return context.WithValue(ctx, callStateKey{}, &callState{})
+}
+
+// SetCallState records the session state loaded for the current tool call,
+// nil makes the next CallState load it again
+func SetCallState(ctx context.Context, state *SessionState) {
+	if call, ok := ctx.Value(callStateKey{}).(*callState); ok {
+		call.state = state
+	}
+}
+
+// CallState returns the session state of the current tool call, loading it
+// from the session manager if it was not recorded yet
+func CallState(ctx context.Context, sessionId string) (*SessionState, error) {
+	call, ok := ctx.Value(callStateKey{}).(*callState)
+	if ok && call.state != nil && call.state.SessionID == sessionId {
+		return call.state, nil
+	}
+
+	state, err := GetSessionManager().GetState(sessionId)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		call.state = state
+	}
+	return state, nil
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"time"
 
+	"github.com/tailabs/mcp-milvus/internal/auth"
+	"github.com/tailabs/mcp-milvus/internal/metrics"
+
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/sirupsen/logrus"
 )
@@ -21,6 +25,8 @@ func RegisterSessionEventCallbacks() {
 				"session_id": sessionID,
 				"address":    state.ConnConfig.Address,
 				"database":   state.ConnConfig.DBName,
+				"profile":    state.ConnConfig.Profile,
+				"principal":  state.Principal,
 			}).Info("Session created")
 		case SessionRemoved:
 			logrus.WithFields(logrus.Fields{
@@ -48,6 +54,13 @@ func RegisterSessionEventCallbacks() {
 		}
 	})
 
+	// Export lifecycle events, accesses are already counted as tool calls
+	sessionManager.AddEventCallback(func(event SessionEvent, sessionID string, state *SessionState) {
+		if event != SessionAccessed {
+			metrics.SessionEvents.WithLabelValues(string(event)).Inc()
+		}
+	})
+
 	// Add performance monitoring callback
 	sessionManager.AddEventCallback(func(event SessionEvent, sessionID string, state *SessionState) {
 		if event == SessionAccessed {
@@ -71,20 +84,57 @@ func RegisterSessionEventCallbacks() {
 func NewSessionAwareHooks() *server.Hooks {
 	hooks := &server.Hooks{}
 
-	hooks.AddOnRegisterSession(func(ctx context.Context, sessionCli server.ClientSession) {
-		sessionID := sessionCli.SessionID()
-		logrus.WithField("session_id", sessionID).Info("Session registered")
+	onRegister := func(ctx context.Context, sessionID string) {
+		// The HTTP transports authenticate the handshake, bind its principal to the session
+		if principal := auth.PrincipalFromContext(ctx); principal != nil {
+			if err := BindPrincipal(sessionID, principal); err != nil {
+				logrus.WithFields(logrus.Fields{
+					"session_id": sessionID,
+					"error":      err,
+				}).Warn("Failed to bind principal to session")
+			}
+		}
+		logrus.WithFields(logrus.Fields{
+			"session_id": sessionID,
+			"principal":  principalSubject(sessionID),
+		}).Info("Session registered")
 
 		sessionManager := GetSessionManager()
 		if _, err := sessionManager.GetState(sessionID); err == nil {
 			sessionManager.SetSessionMetadata(sessionID, "client_connected_at", time.Now())
 			sessionManager.SetSessionMetadata(sessionID, "client_type", "mcp_client")
 		}
+	}
+
+	hooks.AddOnRegisterSession(func(ctx context.Context, sessionCli server.ClientSession) {
+		if isStreamableSession(sessionCli) {
+			return
+		}
+		onRegister(ctx, sessionCli.SessionID())
+	})
+
+	// Streamable HTTP sessions are never registered with the server on POST requests,
+	// their lifetime starts with a successful initialize instead
+	hooks.AddAfterInitialize(func(ctx context.Context, id any, message *mcp.InitializeRequest, result *mcp.InitializeResult) {
+		sessionCli := server.ClientSessionFromContext(ctx)
+		if sessionCli == nil || !isStreamableSession(sessionCli) {
+			return
+		}
+		onRegister(ctx, sessionCli.SessionID())
 	})
 
 	hooks.AddOnUnregisterSession(func(ctx context.Context, sessionCli server.ClientSession) {
 		sessionID := sessionCli.SessionID()
+
+		// A streamable HTTP session only unregisters when its GET notification stream
+		// closes. The client may open a new stream later, so the Milvus client is kept
+		// until the session is terminated or expires.
+		if isStreamableSession(sessionCli) {
+			logrus.WithField("session_id", sessionID).Debug("Session notification stream closed")
+			return
+		}
 		logrus.WithField("session_id", sessionID).Info("Session unregistered")
+		unbindPrincipal(sessionID)
 
 		sessionManager := GetSessionManager()
 		if err := sessionManager.Remove(sessionID); err != nil {
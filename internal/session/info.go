@@ -0,0 +1,37 @@
+package session
+
+import "time"
+
+// SessionInfo summarizes a session for agents and operators, without credentials
+type SessionInfo struct {
+	SessionID    string                 `json:"session_id"`
+	Principal    string                 `json:"principal,omitempty"`
+	Address      string                 `json:"address"`
+	Database     string                 `json:"database"`
+	Profile      string                 `json:"profile,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+	LastAccessed time.Time              `json:"last_accessed"`
+	AccessCount  int64                  `json:"access_count"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Info returns the credential-free summary of the session
+func (s *SessionState) Info() SessionInfo {
+	info := SessionInfo{
+		SessionID:    s.SessionID,
+		Principal:    s.Principal,
+		Database:     "default",
+		CreatedAt:    s.CreatedAt,
+		LastAccessed: s.LastAccessed,
+		AccessCount:  s.AccessCount,
+		Metadata:     s.Metadata,
+	}
+	if s.ConnConfig != nil {
+		info.Address = s.ConnConfig.Address
+		info.Profile = s.ConnConfig.Profile
+		if s.ConnConfig.DBName != "" {
+			info.Database = s.ConnConfig.DBName
+		}
+	}
+	return info
+}
@@ -0,0 +1,340 @@
+package session
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/milvus-io/milvus/client/v2/milvusclient"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// newClient and closeClient are replaced in tests so sessions can be managed without a Milvus server
+	newClient = func(ctx context.Context, config *milvusclient.ClientConfig) (*milvusclient.Client, error) {
+		return milvusclient.New(ctx, config)
+	}
+	closeClient = func(client *milvusclient.Client) error {
+		return client.Close(context.Background())
+	}
+	// checkClient probes whether the Milvus server behind a client answers
+	checkClient = func(ctx context.Context, client *milvusclient.Client) error {
+		_, err := client.GetServerVersion(ctx, milvusclient.NewGetServerVersionOption())
+		return err
+	}
+
+	// replacedClientGrace is how long a replaced client stays open, so calls that
+	// fetched it before the reconnect can finish on it
+	replacedClientGrace = 2 * time.Minute
+)
+
+const (
+	// healthCheckTimeout bounds each health check and reconnect attempt
+	healthCheckTimeout = 5 * time.Second
+	// healthCheckFailures is the number of consecutive failed checks before a client
+	// is reported unavailable and reconnected, so one slow answer on a busy server
+	// does not replace a working client
+	healthCheckFailures = 3
+)
+
+// ErrUnavailable is returned while a Milvus server fails health checks and its client
+// reconnects in the background, callers may retry the call later
+var ErrUnavailable = errors.New("milvus unavailable, retrying")
+
+// clientPool shares one Milvus client between all sessions with the same address,
+// credentials, TLS settings and database, closing it when the last session releases it.
+// Each client is health checked in the background and replaced when its server stops answering.
+type clientPool struct {
+	mu             sync.Mutex
+	clients        map[string]*pooledClient
+	maxConnections int
+	healthInterval time.Duration
+}
+
+type pooledClient struct {
+	config *ConnConfig
+	client *milvusclient.Client
+	refs   int
+
+	// ready is closed once the client is connected or err is set
+	ready chan struct{}
+	err   error
+
+	// failures counts the consecutive failed health checks
+	failures int
+	// unavailable is set while health checks fail, until a reconnect succeeds
+	unavailable error
+	// stop ends the health checker once the client is closed
+	stop chan struct{}
+}
+
+// lease is a session's reference to a pooled client
+type lease struct {
+	key    string
+	pooled *pooledClient
+	// client is the client when the lease was taken, clientPool.client returns the current one
+	client *milvusclient.Client
+}
+
+// newClientPool creates a pool, healthInterval 0 disables health checks
+func newClientPool(maxConnections int, healthInterval time.Duration) *clientPool {
+	return &clientPool{
+		clients:        make(map[string]*pooledClient),
+		maxConnections: maxConnections,
+		healthInterval: healthInterval,
+	}
+}
+
+// poolKey identifies the connection of a config, the profile name is only a label
+// and does not affect it. Credentials are hashed so they never appear in the pool.
+func poolKey(config *ConnConfig) (string, error) {
+	keyConfig := *config
+	keyConfig.Profile = ""
+	if keyConfig.DBName == "" {
+		keyConfig.DBName = "default"
+	}
+
+	data, err := json.Marshal(keyConfig)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// acquire returns a lease on the client for config, connecting a new one if no session shares it yet
+func (p *clientPool) acquire(ctx context.Context, config *ConnConfig) (*lease, error) {
+	key, err := poolKey(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse milvus config: %w", err)
+	}
+
+	milvusClientConfig, err := config.ToMilvusClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse milvus config: %w", err)
+	}
+
+	p.mu.Lock()
+	if pooled, ok := p.clients[key]; ok {
+		// Another session is connecting or connected already, share its client
+		pooled.refs++
+		p.mu.Unlock()
+		<-pooled.ready
+		if pooled.err != nil {
+			return nil, pooled.err
+		}
+		p.mu.Lock()
+		client := pooled.client
+		p.mu.Unlock()
+		return &lease{key: key, pooled: pooled, client: client}, nil
+	}
+	if p.maxConnections > 0 && len(p.clients) >= p.maxConnections {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("maximum number of milvus connections (%d) reached", p.maxConnections)
+	}
+	pooled := &pooledClient{
+		config: config,
+		refs:   1,
+		ready:  make(chan struct{}),
+		stop:   make(chan struct{}),
+	}
+	p.clients[key] = pooled
+	p.mu.Unlock()
+
+	// RetryInterceptor not flexible
+	// issue:https://github.com/milvus-io/milvus/issues/42949
+	client, err := newClient(ctx, milvusClientConfig)
+
+	p.mu.Lock()
+	if err != nil {
+		delete(p.clients, key)
+		pooled.err = fmt.Errorf("failed to create milvus client: %w", err)
+	} else {
+		pooled.client = client
+	}
+	connections := len(p.clients)
+	close(pooled.ready)
+	p.mu.Unlock()
+
+	if pooled.err != nil {
+		return nil, pooled.err
+	}
+	logrus.WithFields(logrus.Fields{
+		"address":     config.Address,
+		"database":    config.DBName,
+		"connections": connections,
+	}).Info("Milvus connection opened")
+
+	if p.healthInterval > 0 {
+		go p.watch(pooled)
+	}
+	return &lease{key: key, pooled: pooled, client: client}, nil
+}
+
+// client returns the current client of a lease, which changes when the pool reconnects
+func (p *clientPool) client(l *lease) (*milvusclient.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if l.pooled.unavailable != nil {
+		return nil, l.pooled.unavailable
+	}
+	return l.pooled.client, nil
+}
+
+// release drops a lease, closing the client once no session uses it anymore
+func (p *clientPool) release(l *lease) {
+	if l == nil {
+		return
+	}
+
+	p.mu.Lock()
+	pooled, ok := p.clients[l.key]
+	if !ok || pooled != l.pooled {
+		p.mu.Unlock()
+		return
+	}
+	pooled.refs--
+	if pooled.refs > 0 {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.clients, l.key)
+	close(pooled.stop)
+	client := pooled.client
+	connections := len(p.clients)
+	p.mu.Unlock()
+
+	if err := closeClient(client); err != nil {
+		logrus.WithField("error", err).Warn("Failed to close milvus client")
+	}
+	logrus.WithField("connections", connections).Info("Milvus connection closed")
+}
+
+// size returns the number of open clients
+func (p *clientPool) size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.clients)
+}
+
+// watch health checks a client until it is closed
+func (p *clientPool) watch(pooled *pooledClient) {
+	ticker := time.NewTicker(p.healthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-pooled.stop:
+			return
+		case <-ticker.C:
+			p.check(pooled)
+		}
+	}
+}
+
+// check probes a client and reconnects it with the stored config, so sessions keep
+// their database, when the server does not answer
+func (p *clientPool) check(pooled *pooledClient) {
+	p.mu.Lock()
+	client := pooled.client
+	p.mu.Unlock()
+
+	l := logrus.WithFields(logrus.Fields{
+		"address":  pooled.config.Address,
+		"database": pooled.config.DBName,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	err := checkClient(ctx, client)
+	cancel()
+
+	p.mu.Lock()
+	if err == nil {
+		recovered := pooled.unavailable != nil
+		pooled.failures = 0
+		pooled.unavailable = nil
+		p.mu.Unlock()
+		if recovered {
+			l.Info("Milvus connection recovered")
+		}
+		return
+	}
+	pooled.failures++
+	if pooled.failures < healthCheckFailures {
+		p.mu.Unlock()
+		l.WithFields(logrus.Fields{"error": err, "failures": pooled.failures}).Debug("Milvus health check failed")
+		return
+	}
+	if pooled.unavailable == nil {
+		l.WithField("error", err).Warn("Milvus health check failed, reconnecting")
+	}
+	pooled.unavailable = fmt.Errorf("%w: %s: %v", ErrUnavailable, pooled.config.Address, err)
+	p.mu.Unlock()
+
+	p.reconnect(pooled, l)
+}
+
+// reconnect replaces the client of pooled with a new connection, keeping the old one on failure
+func (p *clientPool) reconnect(pooled *pooledClient, l *logrus.Entry) {
+	milvusClientConfig, err := pooled.config.ToMilvusClientConfig()
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	client, err := newClient(ctx, milvusClientConfig)
+	cancel()
+	if err != nil {
+		l.WithField("error", err).Debug("Milvus reconnect failed")
+		return
+	}
+
+	p.mu.Lock()
+	select {
+	case <-pooled.stop:
+		// The last session released the client meanwhile
+		p.mu.Unlock()
+		closeClient(client)
+		return
+	default:
+	}
+	previous := pooled.client
+	pooled.client = client
+	pooled.failures = 0
+	pooled.unavailable = nil
+	p.mu.Unlock()
+
+	// Calls still running on the previous client keep it until the grace period ends
+	time.AfterFunc(replacedClientGrace, func() {
+		if err := closeClient(previous); err != nil {
+			l.WithField("error", err).Debug("Failed to close broken milvus client")
+		}
+	})
+	l.Info("Milvus connection re-established")
+}
+
+// health returns the health check state of the client pooled for config, checked is
+// false when no connected client is pooled for it or health checks are disabled
+func (p *clientPool) health(config *ConnConfig) (checked bool, err error) {
+	if p.healthInterval <= 0 {
+		return false, nil
+	}
+	key, err := poolKey(config)
+	if err != nil {
+		return false, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pooled, ok := p.clients[key]
+	if !ok || pooled.client == nil {
+		return false, nil
+	}
+	return true, pooled.unavailable
+}
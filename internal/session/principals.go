@@ -0,0 +1,81 @@
+package session
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tailabs/mcp-milvus/internal/auth"
+)
+
+// principalRegistry remembers which authenticated principal owns each MCP session,
+// so a session ID leaked to another client cannot be used with different credentials
+type principalRegistry struct {
+	mu       sync.Mutex
+	bindings map[string]*principalBinding
+}
+
+type principalBinding struct {
+	principal *auth.Principal
+	lastSeen  time.Time
+}
+
+var principals = &principalRegistry{
+	bindings: make(map[string]*principalBinding),
+}
+
+// BindPrincipal binds the principal to the session on first use and verifies that
+// later requests come from the same principal
+func BindPrincipal(sessionID string, principal *auth.Principal) error {
+	if principal == nil {
+		return nil
+	}
+
+	principals.mu.Lock()
+	defer principals.mu.Unlock()
+
+	now := time.Now()
+	if binding, ok := principals.bindings[sessionID]; ok {
+		if binding.principal.Subject != principal.Subject {
+			return fmt.Errorf("session %s belongs to another principal", sessionID)
+		}
+		binding.principal = principal
+		binding.lastSeen = now
+		return nil
+	}
+
+	// Streamable HTTP sessions that are never terminated would otherwise leak their binding
+	for id, binding := range principals.bindings {
+		if now.Sub(binding.lastSeen) > managerOptions.DefaultTTL {
+			delete(principals.bindings, id)
+		}
+	}
+	principals.bindings[sessionID] = &principalBinding{principal: principal, lastSeen: now}
+	return nil
+}
+
+// PrincipalOf returns the principal bound to the session, or nil if there is none
+func PrincipalOf(sessionID string) *auth.Principal {
+	principals.mu.Lock()
+	defer principals.mu.Unlock()
+
+	if binding, ok := principals.bindings[sessionID]; ok {
+		return binding.principal
+	}
+	return nil
+}
+
+// unbindPrincipal forgets the session's principal once the session is gone
+func unbindPrincipal(sessionID string) {
+	principals.mu.Lock()
+	defer principals.mu.Unlock()
+	delete(principals.bindings, sessionID)
+}
+
+// principalSubject returns the subject bound to the session for logging, or ""
+func principalSubject(sessionID string) string {
+	if principal := PrincipalOf(sessionID); principal != nil {
+		return principal.Subject
+	}
+	return ""
+}
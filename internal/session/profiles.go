@@ -0,0 +1,180 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// profileRegistry holds the server-side connection profiles agents can attach to
+// without ever seeing the underlying credentials
+type profileRegistry struct {
+	mu             sync.RWMutex
+	profiles       map[string]ConnConfig
+	defaultProfile string
+	allowCustom    bool
+}
+
+var profiles = &profileRegistry{
+	profiles:    make(map[string]ConnConfig),
+	allowCustom: true,
+}
+
+// SetProfiles replaces the configured connection profiles
+// defaultProfile may be empty; allowCustom controls whether agents may still
+// connect with a raw address and credentials
+func SetProfiles(configs map[string]ConnConfig, defaultProfile string, allowCustom bool) error {
+	if defaultProfile != "" {
+		if _, ok := configs[defaultProfile]; !ok {
+			return fmt.Errorf("default profile not found: %s", defaultProfile)
+		}
+	}
+
+	copied := make(map[string]ConnConfig, len(configs))
+	for name, config := range configs {
+		copied[name] = config
+	}
+
+	profiles.mu.Lock()
+	defer profiles.mu.Unlock()
+	profiles.profiles = copied
+	profiles.defaultProfile = defaultProfile
+	profiles.allowCustom = allowCustom
+	return nil
+}
+
+// GetProfile returns a copy of the named connection profile
+func GetProfile(name string) (*ConnConfig, error) {
+	profiles.mu.RLock()
+	defer profiles.mu.RUnlock()
+
+	config, ok := profiles.profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("connection profile not found: %s (available: %s)", name, strings.Join(profiles.names(), ", "))
+	}
+	config.Profile = name
+	return &config, nil
+}
+
+// ProfileNames returns the sorted names of all configured profiles
+func ProfileNames() []string {
+	profiles.mu.RLock()
+	defer profiles.mu.RUnlock()
+	return profiles.names()
+}
+
+func (r *profileRegistry) names() []string {
+	names := make([]string, 0, len(r.profiles))
+	for name := range r.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultProfile returns the name of the profile new sessions attach to automatically
+func DefaultProfile() string {
+	profiles.mu.RLock()
+	defer profiles.mu.RUnlock()
+	return profiles.defaultProfile
+}
+
+// CustomConnectionsAllowed reports whether agents may connect with their own address and credentials
+func CustomConnectionsAllowed() bool {
+	profiles.mu.RLock()
+	defer profiles.mu.RUnlock()
+	return profiles.allowCustom
+}
+
+// AttachDefaultProfile connects the session using the default profile
+// It returns false if no default profile is configured
+func AttachDefaultProfile(sessionId string) (bool, error) {
+	name := DefaultProfile()
+	if name == "" {
+		return false, nil
+	}
+
+	config, err := GetProfile(name)
+	if err != nil {
+		return false, err
+	}
+	if err := GetSessionManager().Set(sessionId, config); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ProbeProfiles checks that the server of every configured profile answers, returning
+// the error of each unreachable profile by name. Profiles whose connection is pooled and
+// health checked report the pool's state, the others are dialed concurrently. Probes
+// still running when ctx ends fail with its error.
+func ProbeProfiles(ctx context.Context) map[string]error {
+	var pool *clientPool
+	switch m := GetSessionManager().(type) {
+	case *SessionManager:
+		pool = m.pool
+	case *SharedSessionManager:
+		pool = m.local.pool
+	}
+	return probeProfiles(ctx, pool)
+}
+
+func probeProfiles(ctx context.Context, pool *clientPool) map[string]error {
+	type result struct {
+		name string
+		err  error
+	}
+
+	names := ProfileNames()
+	pending := make(map[string]bool, len(names))
+	// Buffered so probes still running when ctx ends can finish and close their clients
+	results := make(chan result, len(names))
+	for _, name := range names {
+		config, err := GetProfile(name)
+		if err != nil {
+			continue
+		}
+		pending[name] = true
+		go func() {
+			if pool != nil {
+				if checked, err := pool.health(config); checked {
+					results <- result{name, err}
+					return
+				}
+			}
+			results <- result{name, probe(ctx, config)}
+		}()
+	}
+
+	failed := make(map[string]error)
+	for len(pending) > 0 {
+		select {
+		case r := <-results:
+			delete(pending, r.name)
+			if r.err != nil {
+				failed[r.name] = r.err
+			}
+		case <-ctx.Done():
+			for name := range pending {
+				failed[name] = ctx.Err()
+			}
+			return failed
+		}
+	}
+	return failed
+}
+
+func probe(ctx context.Context, config *ConnConfig) error {
+	milvusClientConfig, err := config.ToMilvusClientConfig()
+	if err != nil {
+		return err
+	}
+	client, err := newClient(ctx, milvusClientConfig)
+	if err != nil {
+		return err
+	}
+	defer closeClient(client)
+	return checkClient(ctx, client)
+}
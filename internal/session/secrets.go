@@ -0,0 +1,73 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+)
+
+// secretFields are the secrets of a custom connection, which only reach the shared store encrypted
+type secretFields struct {
+	Token  string `json:"token,omitempty"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// sealer encrypts connection credentials with AES-256-GCM before they are stored
+type sealer struct {
+	aead cipher.AEAD
+}
+
+// newSealer returns a sealer for the 32 byte key, or nil if key is empty
+func newSealer(key []byte) (*sealer, error) {
+	if len(key) == 0 {
+		return nil, nil
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("session store encryption key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &sealer{aead: aead}, nil
+}
+
+// seal encrypts the credentials, binding them to the session so a sealed value
+// copied to another session's record does not decrypt
+func (s *sealer) seal(sessionId string, creds secretFields) ([]byte, error) {
+	plaintext, err := json.Marshal(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(plaintext)+s.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return s.aead.Seal(nonce, nonce, plaintext, []byte(sessionId)), nil
+}
+
+// open decrypts credentials sealed for the session
+func (s *sealer) open(sessionId string, sealed []byte) (secretFields, error) {
+	var creds secretFields
+	if len(sealed) < s.aead.NonceSize() {
+		return creds, fmt.Errorf("sealed credentials are too short")
+	}
+
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, []byte(sessionId))
+	if err != nil {
+		return creds, fmt.Errorf("failed to decrypt credentials: %w", err)
+	}
+	if err := json.Unmarshal(plaintext, &creds); err != nil {
+		return creds, err
+	}
+	return creds, nil
+}
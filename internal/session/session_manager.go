@@ -2,49 +2,197 @@ package session
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"sort"
 	"strings"
 	"sync"
-	"sync/atomic"
 	"time"
 
+	"github.com/tailabs/mcp-milvus/internal/metrics"
+	"github.com/tailabs/mcp-milvus/internal/resilience"
+	"github.com/tailabs/mcp-milvus/internal/session/store"
+	"github.com/tailabs/mcp-milvus/internal/tracing"
+
 	"github.com/dgraph-io/ristretto"
 	"github.com/milvus-io/milvus/client/v2/milvusclient"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 )
 
 type ConnConfig struct {
 	Address string `json:"address"`
 	Token   string `json:"token"`
 	DBName  string `json:"db_name"`
+
+	// APIKey authenticates against managed clusters such as Zilliz Cloud
+	APIKey string `json:"api_key,omitempty"`
+
+	// TLS settings, a custom CA or client certificate implies EnableTLS
+	EnableTLS      bool   `json:"enable_tls,omitempty"`
+	CACertFile     string `json:"ca_cert_file,omitempty"`
+	ClientCertFile string `json:"client_cert_file,omitempty"`
+	ClientKeyFile  string `json:"client_key_file,omitempty"`
+	ServerName     string `json:"server_name,omitempty"`
+
+	// Profile is the name of the server-side connection profile, if any
+	Profile string `json:"profile,omitempty"`
+}
+
+// defaultDialOptions mirrors the Milvus client's default gRPC options, which it
+// drops as soon as custom dial options are passed
+var defaultDialOptions = []grpc.DialOption{
+	grpc.WithKeepaliveParams(keepalive.ClientParameters{
+		Time:                5 * time.Second,
+		Timeout:             10 * time.Second,
+		PermitWithoutStream: true,
+	}),
+	grpc.WithConnectParams(grpc.ConnectParams{
+		Backoff: backoff.Config{
+			BaseDelay:  100 * time.Millisecond,
+			Multiplier: 1.6,
+			Jitter:     0.2,
+			MaxDelay:   3 * time.Second,
+		},
+		MinConnectTimeout: 3 * time.Second,
+	}),
+	grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(math.MaxInt32)),
 }
 
 func (c *ConnConfig) ToMilvusClientConfig() (*milvusclient.ClientConfig, error) {
-	if len(c.Token) == 0 {
-		return &milvusclient.ClientConfig{
-			Address: c.Address,
-			DBName:  c.DBName,
-		}, nil
+	config := &milvusclient.ClientConfig{
+		Address:       c.Address,
+		DBName:        c.DBName,
+		APIKey:        c.APIKey,
+		EnableTLSAuth: c.EnableTLS,
+		// Every call of the client gets one span, retries and the circuit breaker of the address
+		// apply inside it, metrics observe each attempt
+		DialOptions: append(append([]grpc.DialOption{}, defaultDialOptions...),
+			grpc.WithChainUnaryInterceptor(
+				tracing.UnaryClientInterceptor(c.Address),
+				resilience.UnaryClientInterceptor(c.Address),
+				metrics.UnaryClientInterceptor,
+			)),
+	}
+
+	// The token is either username:password or an API key
+	if len(c.Token) > 0 {
+		if len(c.APIKey) > 0 {
+			return nil, fmt.Errorf("set either token or api_key, not both")
+		}
+		username, password, found := strings.Cut(c.Token, ":")
+		if found {
+			config.Username = username
+			config.Password = password
+		} else {
+			config.APIKey = c.Token
+		}
+	}
+
+	if c.hasCustomTLS() {
+		tlsConfig, err := c.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		config.EnableTLSAuth = true
+		config.DialOptions = append(config.DialOptions, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	}
+
+	return config, nil
+}
+
+// hasCustomTLS reports whether the connection needs more than the system default TLS setup
+func (c *ConnConfig) hasCustomTLS() bool {
+	return c.CACertFile != "" || c.ClientCertFile != "" || c.ClientKeyFile != "" || c.ServerName != ""
+}
+
+// tlsConfig builds the TLS client configuration from the CA bundle, client certificate and server name
+func (c *ConnConfig) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.ServerName,
+	}
+
+	if c.CACertFile != "" {
+		caPEM, err := os.ReadFile(c.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no valid certificates found in CA bundle: %s", c.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
 	}
 
-	tokenSlice := strings.Split(c.Token, ":")
-	if len(tokenSlice) != 2 {
-		return nil, fmt.Errorf("invalid token format, e.g. username:password")
+	if c.ClientCertFile != "" || c.ClientKeyFile != "" {
+		if c.ClientCertFile == "" || c.ClientKeyFile == "" {
+			return nil, fmt.Errorf("client certificate and key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(c.ClientCertFile, c.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
 
-	return &milvusclient.ClientConfig{
-		Address:  c.Address,
-		Username: tokenSlice[0],
-		Password: tokenSlice[1],
-		DBName:   c.DBName,
-	}, nil
+	return tlsConfig, nil
 }
 
 var (
 	sessionManager SessionManagerInterface
 	once           sync.Once
+
+	// managerOptions are used when the global session manager is first created
+	managerOptions = DefaultOptions()
 )
 
+// Options configures a SessionManager and its Ristretto cache
+type Options struct {
+	MaxSessions int
+	DefaultTTL  time.Duration
+
+	// MaxConnections limits the Milvus clients shared by the sessions, 0 means no limit
+	MaxConnections int
+	// HealthCheckInterval is how often Milvus clients are checked and reconnected, 0 disables it
+	HealthCheckInterval time.Duration
+
+	NumCounters int64 // Number of counters, should be 10x the number of max items
+	MaxCost     int64 // Maximum cost
+	BufferItems int64 // Buffer size
+
+	// Store shares sessions between replicas, nil keeps them in this process only
+	Store store.Store
+	// StoreKey encrypts the credentials of custom connections kept in Store
+	StoreKey []byte
+}
+
+// DefaultOptions returns the default session manager options
+func DefaultOptions() Options {
+	return Options{
+		MaxSessions:         1000,
+		DefaultTTL:          1 * time.Hour,
+		MaxConnections:      100,
+		HealthCheckInterval: 30 * time.Second,
+		NumCounters:         1e7,
+		MaxCost:             1 << 30, // 1GB
+		BufferItems:         64,
+	}
+}
+
+// Configure sets the options of the global session manager
+// It must be called before the first GetSessionManager call to take effect
+func Configure(opts Options) {
+	managerOptions = opts
+}
+
 // SessionEvent represents different session events
 type SessionEvent string
 
@@ -58,12 +206,16 @@ const (
 // SessionState holds detailed session information
 type SessionState struct {
 	SessionID    string
+	Principal    string // Subject of the authenticated principal, empty without inbound auth
 	ConnConfig   *ConnConfig
 	Client       *milvusclient.Client
 	CreatedAt    time.Time
 	LastAccessed time.Time
 	AccessCount  int64
 	Metadata     map[string]interface{}
+
+	// lease is the session's reference to its pooled client
+	lease *lease
 }
 
 // SessionEventCallback defines the callback function for session events
@@ -75,13 +227,23 @@ type SessionEventCallback func(event SessionEvent, sessionID string, state *Sess
 type SessionManagerInterface interface {
 	// Core session operations
 	Get(sessionId string) (*milvusclient.Client, error)
+	// Access is Get returning the whole session state, for callers that need more than the client
+	Access(sessionId string) (*SessionState, error)
 	GetState(sessionId string) (*SessionState, error)
 	Set(sessionId string, config *ConnConfig) error
+	UseDatabase(sessionId string, dbName string) error
+	// GetForDatabase returns a client for dbName without switching the session, "" means the current database
+	GetForDatabase(sessionId string, dbName string) (*milvusclient.Client, error)
 	Remove(sessionId string) error
 	Clear() error
 	Size() int
+	List() []*SessionState
 	Close() error
 
+	// Disconnect removes a session on behalf of an operator and refuses its further calls
+	Disconnect(sessionId string) error
+	IsDisconnected(sessionId string) bool
+
 	// Event callback management
 	AddEventCallback(callback SessionEventCallback)
 
@@ -104,40 +266,97 @@ type SessionManager struct {
 	cleanupTicker *time.Ticker
 	stopChan      chan struct{}
 
-	// Session counter (Ristretto doesn't have built-in counting)
-	sessionCount int64
+	// sessions indexes the lease of every live session, Ristretto can neither count nor iterate its entries
+	sessions   map[string]*lease
+	sessionsMu sync.Mutex
+
+	// databases holds the clients of per-call database overrides by session and database,
+	// guarded by sessionsMu
+	databases map[string]map[string]*lease
+
+	// disconnected records when operators disconnected a session, guarded by sessionsMu
+	disconnected map[string]time.Time
+
+	// pool shares clients between sessions with the same connection
+	pool *clientPool
+
+	// locks serialize the updates of a session's cached state, see lockSession
+	locks [sessionLockStripes]sync.Mutex
+}
+
+// sessionLockStripes is the number of locks the sessions are spread over
+const sessionLockStripes = 64
+
+// lockSession locks the session against concurrent updates of its cached state, so an
+// update never writes back a state, and with it a lease, that another one replaced
+// Blocking cache calls such as Wait must not be made with the lock held, onEvict runs on
+// the cache's goroutine
+func (s *SessionManager) lockSession(sessionId string) *sync.Mutex {
+	h := fnv.New32a()
+	h.Write([]byte(sessionId))
+	lock := &s.locks[h.Sum32()%sessionLockStripes]
+	lock.Lock()
+	return lock
+}
+
+// holds reports whether l is still the session's lease
+func (s *SessionManager) holds(sessionId string, l *lease) bool {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	return s.sessions[sessionId] == l
 }
 
 // GetSessionManager returns the global session manager instance (singleton pattern)
 func GetSessionManager() SessionManagerInterface {
 	once.Do(func() {
-		sessionManager = NewSessionManager()
+		local := NewSessionManagerWithOptions(managerOptions)
+		if managerOptions.Store != nil {
+			shared, err := NewSharedSessionManager(local, managerOptions.Store, managerOptions.StoreKey)
+			if err != nil {
+				logrus.Fatalf("Failed to create shared session manager: %v", err)
+			}
+			sessionManager = shared
+		} else {
+			sessionManager = local
+		}
 	})
 	return sessionManager
 }
 
 // NewSessionManager creates a new session manager instance with Ristretto cache
 func NewSessionManager() *SessionManager {
+	return NewSessionManagerWithOptions(DefaultOptions())
+}
+
+// NewSessionManagerWithOptions creates a new session manager instance with the given options
+func NewSessionManagerWithOptions(opts Options) *SessionManager {
+	sm := &SessionManager{
+		callbacks:    make([]SessionEventCallback, 0),
+		maxSessions:  opts.MaxSessions,
+		defaultTTL:   opts.DefaultTTL,
+		stopChan:     make(chan struct{}),
+		sessions:     make(map[string]*lease),
+		databases:    make(map[string]map[string]*lease),
+		disconnected: make(map[string]time.Time),
+		pool:         newClientPool(opts.MaxConnections, opts.HealthCheckInterval),
+	}
+
 	// Create Ristretto cache configuration
 	config := &ristretto.Config{
-		NumCounters: 1e7,     // Number of counters, should be 10x the number of max items
-		MaxCost:     1 << 30, // Maximum cost (1GB)
-		BufferItems: 64,      // Buffer size
+		NumCounters: opts.NumCounters,
+		MaxCost:     opts.MaxCost,
+		BufferItems: opts.BufferItems,
+		// Each session costs 1, so MaxCost is the session limit
+		IgnoreInternalCost: true,
+		OnEvict:            sm.onEvict,
+		OnReject:           sm.onReject,
 	}
 
 	cache, err := ristretto.NewCache(config)
 	if err != nil {
 		logrus.Fatalf("Failed to create Ristretto cache: %v", err)
 	}
-
-	sm := &SessionManager{
-		cache:        cache,
-		callbacks:    make([]SessionEventCallback, 0),
-		maxSessions:  100,
-		defaultTTL:   1 * time.Hour,
-		stopChan:     make(chan struct{}),
-		sessionCount: 0,
-	}
+	sm.cache = cache
 
 	// Start background cleanup goroutine (minimal monitoring)
 	sm.startBackgroundMonitoring()
@@ -146,7 +365,7 @@ func NewSessionManager() *SessionManager {
 }
 
 // startBackgroundMonitoring starts a goroutine for basic monitoring
-// Note: Ristretto handles expiration automatically, so we only log basic stats
+// Note: Ristretto handles expiration automatically and reports it through onEvict, so we only log basic stats
 func (s *SessionManager) startBackgroundMonitoring() {
 	s.cleanupTicker = time.NewTicker(15 * time.Minute)
 
@@ -154,7 +373,7 @@ func (s *SessionManager) startBackgroundMonitoring() {
 		for {
 			select {
 			case <-s.cleanupTicker.C:
-				logrus.WithField("active_sessions", atomic.LoadInt64(&s.sessionCount)).Debug("Session manager stats")
+				logrus.WithField("active_sessions", s.Size()).Debug("Session manager stats")
 			case <-s.stopChan:
 				return
 			}
@@ -162,6 +381,64 @@ func (s *SessionManager) startBackgroundMonitoring() {
 	}()
 }
 
+// onEvict runs when Ristretto drops a session because its TTL passed or the cache is full
+func (s *SessionManager) onEvict(item *ristretto.Item) {
+	state, ok := item.Value.(*SessionState)
+	if !ok || !s.release(state) {
+		return
+	}
+
+	s.triggerEvent(SessionExpired, state.SessionID, state)
+
+	logrus.WithField("session", state.SessionID).Info("Session expired")
+}
+
+// onReject runs when Ristretto's admission policy refuses to store a new session
+func (s *SessionManager) onReject(item *ristretto.Item) {
+	state, ok := item.Value.(*SessionState)
+	if !ok || !s.release(state) {
+		return
+	}
+
+	logrus.WithField("session", state.SessionID).Warn("Session rejected by cache")
+}
+
+// release removes the session from the index if it still holds the state's lease,
+// and releases that lease along with the session's database overrides
+// It reports whether this call did the release
+func (s *SessionManager) release(state *SessionState) bool {
+	s.sessionsMu.Lock()
+	current, found := s.sessions[state.SessionID]
+	if !found || current != state.lease {
+		s.sessionsMu.Unlock()
+		return false
+	}
+	delete(s.sessions, state.SessionID)
+	databases := s.takeDatabases(state.SessionID)
+	s.sessionsMu.Unlock()
+
+	s.pool.release(state.lease)
+	s.releaseAll(databases)
+	return true
+}
+
+// takeDatabases removes and returns the database override leases of a session
+// The caller must hold sessionsMu
+func (s *SessionManager) takeDatabases(sessionId string) []*lease {
+	var leases []*lease
+	for _, l := range s.databases[sessionId] {
+		leases = append(leases, l)
+	}
+	delete(s.databases, sessionId)
+	return leases
+}
+
+func (s *SessionManager) releaseAll(leases []*lease) {
+	for _, l := range leases {
+		s.pool.release(l)
+	}
+}
+
 // triggerEvent fires all registered callbacks for the given event
 func (s *SessionManager) triggerEvent(event SessionEvent, sessionID string, state *SessionState) {
 	// Use a separate goroutine to handle event triggering to avoid blocking
@@ -192,10 +469,22 @@ func (s *SessionManager) triggerEvent(event SessionEvent, sessionID string, stat
 
 // Get retrieves the Milvus client for the specified session
 func (s *SessionManager) Get(sessionId string) (*milvusclient.Client, error) {
+	state, err := s.Access(sessionId)
+	if err != nil {
+		return nil, err
+	}
+	return state.Client, nil
+}
+
+// Access returns the session state with its current client and records the access
+func (s *SessionManager) Access(sessionId string) (*SessionState, error) {
 	if sessionId == "" {
 		return nil, fmt.Errorf("session ID cannot be empty")
 	}
 
+	lock := s.lockSession(sessionId)
+	defer lock.Unlock()
+
 	// Get session from cache
 	item, found := s.cache.Get(sessionId)
 	if !found {
@@ -207,8 +496,16 @@ func (s *SessionManager) Get(sessionId string) (*milvusclient.Client, error) {
 		return nil, fmt.Errorf("invalid session data for: %s", sessionId)
 	}
 
-	// Get the client reference
-	client := state.Client
+	// The session may have expired since, writing it back would revive a released lease
+	if !s.holds(sessionId, state.lease) {
+		return nil, fmt.Errorf("session not found: %s", sessionId)
+	}
+
+	// Get the current client, which changes when the pool reconnects
+	client, err := s.pool.client(state.lease)
+	if err != nil {
+		return nil, err
+	}
 
 	// Update access statistics (create a copy to avoid race conditions)
 	updatedState := *state
@@ -218,16 +515,12 @@ func (s *SessionManager) Get(sessionId string) (*milvusclient.Client, error) {
 	// Update cache with new state
 	s.cache.SetWithTTL(sessionId, &updatedState, 1, s.defaultTTL)
 
-	// Trigger access event with the updated state copy
-	if updatedState.Metadata != nil {
-		updatedState.Metadata = make(map[string]interface{})
-		for k, v := range state.Metadata {
-			updatedState.Metadata[k] = v
-		}
-	}
-	s.triggerEvent(SessionAccessed, sessionId, &updatedState)
+	// Trigger access event with a copy of the updated state
+	s.triggerEvent(SessionAccessed, sessionId, updatedState.clone())
 
-	return client, nil
+	accessed := updatedState.clone()
+	accessed.Client = client
+	return accessed, nil
 }
 
 // GetState retrieves the complete session state
@@ -247,13 +540,21 @@ func (s *SessionManager) GetState(sessionId string) (*SessionState, error) {
 	}
 
 	// Return a copy to prevent external modification
-	stateCopy := *state
-	stateCopy.Metadata = make(map[string]interface{})
-	for k, v := range state.Metadata {
-		stateCopy.Metadata[k] = v
+	stateCopy := state.clone()
+	if client, err := s.pool.client(state.lease); err == nil {
+		stateCopy.Client = client
 	}
+	return stateCopy, nil
+}
 
-	return &stateCopy, nil
+// clone returns a copy of the state with its own metadata map
+func (s *SessionState) clone() *SessionState {
+	copied := *s
+	copied.Metadata = make(map[string]interface{}, len(s.Metadata))
+	for k, v := range s.Metadata {
+		copied.Metadata[k] = v
+	}
+	return &copied
 }
 
 // Set creates or updates a Milvus client for the specified session
@@ -265,47 +566,60 @@ func (s *SessionManager) Set(sessionId string, config *ConnConfig) error {
 		return fmt.Errorf("connection config cannot be nil")
 	}
 
-	// Check session limit
-	currentCount := atomic.LoadInt64(&s.sessionCount)
-	if currentCount >= int64(s.maxSessions) {
-		return fmt.Errorf("maximum number of sessions (%d) reached", s.maxSessions)
-	}
-
-	// Clean up existing session if it exists
-	if existing, found := s.cache.Get(sessionId); found {
-		if existingState, ok := existing.(*SessionState); ok {
-			s.closeClientSafely(existingState.Client, sessionId)
-		}
+	// Check session limit, replacing an existing session does not count
+	if err := s.checkLimit(sessionId); err != nil {
+		return err
 	}
 
-	// Create new Milvus client
-	milvusClientConfig, err := config.ToMilvusClientConfig()
+	// Share the Milvus client of sessions with the same connection, or create a new one
+	l, err := s.pool.acquire(context.TODO(), config)
 	if err != nil {
-		return fmt.Errorf("failed to parse milvus config: %w", err)
+		return err
 	}
 
-	// RetryInterceptor not flexible
-	// issue:https://github.com/milvus-io/milvus/issues/42949
-	client, err := milvusclient.New(context.TODO(), milvusClientConfig)
-	if err != nil {
-		return fmt.Errorf("failed to create milvus client: %w", err)
+	// Register the new lease, the previous one of the session is only released once
+	// its replacement is connected
+	lock := s.lockSession(sessionId)
+	s.sessionsMu.Lock()
+	previous, replaced := s.sessions[sessionId]
+	if !replaced && len(s.sessions) >= s.maxSessions {
+		s.sessionsMu.Unlock()
+		lock.Unlock()
+		s.pool.release(l)
+		return fmt.Errorf("maximum number of sessions (%d) reached", s.maxSessions)
 	}
+	s.sessions[sessionId] = l
+	total := len(s.sessions)
+	// Database overrides belong to the previous connection
+	overrides := s.takeDatabases(sessionId)
+	s.sessionsMu.Unlock()
 
 	// Create session state
 	now := time.Now()
 	state := &SessionState{
 		SessionID:    sessionId,
+		Principal:    principalSubject(sessionId),
 		ConnConfig:   config,
-		Client:       client,
+		Client:       l.client,
 		CreatedAt:    now,
 		LastAccessed: now,
 		AccessCount:  0,
 		Metadata:     make(map[string]interface{}),
+		lease:        l,
 	}
 
-	// Store in cache
+	// Store in cache, waiting for the buffered write so the session is visible to the next call
 	s.cache.SetWithTTL(sessionId, state, 1, s.defaultTTL)
-	atomic.AddInt64(&s.sessionCount, 1)
+	lock.Unlock()
+	if replaced {
+		s.pool.release(previous)
+	}
+	s.releaseAll(overrides)
+	s.cache.Wait()
+	if _, found := s.cache.Get(sessionId); !found {
+		// onReject has already closed the client and released the session
+		return fmt.Errorf("failed to store session: %s", sessionId)
+	}
 
 	// Trigger creation event
 	s.triggerEvent(SessionCreated, sessionId, state)
@@ -314,18 +628,131 @@ func (s *SessionManager) Set(sessionId string, config *ConnConfig) error {
 		"session":        sessionId,
 		"address":        config.Address,
 		"database":       config.DBName,
-		"total_sessions": atomic.LoadInt64(&s.sessionCount),
+		"profile":        config.Profile,
+		"principal":      state.Principal,
+		"total_sessions": total,
+		"connections":    s.pool.size(),
 	}).Info("Session created successfully")
 
 	return nil
 }
 
+// UseDatabase switches the session to another database
+// The session moves to the pooled client of that database, so other sessions
+// sharing its current client are not affected
+func (s *SessionManager) UseDatabase(sessionId string, dbName string) error {
+	state, err := s.GetState(sessionId)
+	if err != nil {
+		return err
+	}
+
+	config := *state.ConnConfig
+	config.DBName = dbName
+	l, err := s.pool.acquire(context.TODO(), &config)
+	if err != nil {
+		return err
+	}
+
+	// Update the latest cached state, not the copy read before connecting
+	lock := s.lockSession(sessionId)
+	item, found := s.cache.Get(sessionId)
+	current, ok := item.(*SessionState)
+	s.sessionsMu.Lock()
+	if !found || !ok || current.lease != state.lease || s.sessions[sessionId] != state.lease {
+		// The session was removed or reconnected meanwhile
+		s.sessionsMu.Unlock()
+		lock.Unlock()
+		s.pool.release(l)
+		return fmt.Errorf("session changed while switching database: %s", sessionId)
+	}
+	s.sessions[sessionId] = l
+	s.sessionsMu.Unlock()
+
+	updatedState := *current
+	updatedState.ConnConfig = &config
+	updatedState.Client = l.client
+	updatedState.lease = l
+	s.cache.SetWithTTL(sessionId, &updatedState, 1, s.defaultTTL)
+	lock.Unlock()
+
+	s.pool.release(state.lease)
+	s.cache.Wait()
+
+	logrus.WithFields(logrus.Fields{
+		"session":  sessionId,
+		"database": dbName,
+	}).Info("Session switched database")
+	return nil
+}
+
+// GetForDatabase returns a client of the session's connection for another database
+// The client is kept for later calls until the session ends or reconnects
+func (s *SessionManager) GetForDatabase(sessionId string, dbName string) (*milvusclient.Client, error) {
+	client, err := s.Get(sessionId)
+	if err != nil || dbName == "" {
+		return client, err
+	}
+
+	state, err := s.GetState(sessionId)
+	if err != nil {
+		return nil, err
+	}
+	if databaseName(state.ConnConfig.DBName) == databaseName(dbName) {
+		return client, nil
+	}
+
+	s.sessionsMu.Lock()
+	if l, found := s.databases[sessionId][dbName]; found {
+		s.sessionsMu.Unlock()
+		return s.pool.client(l)
+	}
+	s.sessionsMu.Unlock()
+
+	config := *state.ConnConfig
+	config.DBName = dbName
+	l, err := s.pool.acquire(context.TODO(), &config)
+	if err != nil {
+		return nil, err
+	}
+
+	s.sessionsMu.Lock()
+	if s.sessions[sessionId] != state.lease {
+		// The session was removed or reconnected meanwhile
+		s.sessionsMu.Unlock()
+		s.pool.release(l)
+		return nil, fmt.Errorf("session changed while connecting to database %s: %s", dbName, sessionId)
+	}
+	if existing, found := s.databases[sessionId][dbName]; found {
+		// A concurrent call connected first
+		s.sessionsMu.Unlock()
+		s.pool.release(l)
+		return s.pool.client(existing)
+	}
+	if s.databases[sessionId] == nil {
+		s.databases[sessionId] = make(map[string]*lease)
+	}
+	s.databases[sessionId][dbName] = l
+	s.sessionsMu.Unlock()
+	return s.pool.client(l)
+}
+
+// databaseName normalizes the empty database name to Milvus' default database
+func databaseName(dbName string) string {
+	if dbName == "" {
+		return "default"
+	}
+	return dbName
+}
+
 // Remove removes the specified session and cleans up resources
 func (s *SessionManager) Remove(sessionId string) error {
 	if sessionId == "" {
 		return fmt.Errorf("session ID cannot be empty")
 	}
 
+	lock := s.lockSession(sessionId)
+	defer lock.Unlock()
+
 	item, found := s.cache.Get(sessionId)
 	if !found {
 		return fmt.Errorf("session not found: %s", sessionId)
@@ -336,12 +763,14 @@ func (s *SessionManager) Remove(sessionId string) error {
 		return fmt.Errorf("invalid session data for: %s", sessionId)
 	}
 
-	// Close client safely
-	s.closeClientSafely(state.Client, sessionId)
+	// An expiry running concurrently may have cleaned up the session already
+	// release closes the client unless other sessions still share it
+	if !s.release(state) {
+		return fmt.Errorf("session not found: %s", sessionId)
+	}
 
-	// Remove from cache
+	// Remove from cache, Del does not trigger onEvict
 	s.cache.Del(sessionId)
-	atomic.AddInt64(&s.sessionCount, -1)
 
 	// Trigger removal event
 	s.triggerEvent(SessionRemoved, sessionId, state)
@@ -350,31 +779,112 @@ func (s *SessionManager) Remove(sessionId string) error {
 	return nil
 }
 
-// closeClientSafely closes a Milvus client with error handling
-func (s *SessionManager) closeClientSafely(client *milvusclient.Client, sessionId string) {
-	if client != nil {
-		if err := client.Close(context.Background()); err != nil {
-			logrus.WithFields(logrus.Fields{
-				"session": sessionId,
-				"error":   err,
-			}).Warn("Failed to close milvus client for session")
-		}
+// checkLimit fails if a new session would exceed maxSessions
+func (s *SessionManager) checkLimit(sessionId string) error {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	if _, found := s.sessions[sessionId]; !found && len(s.sessions) >= s.maxSessions {
+		return fmt.Errorf("maximum number of sessions (%d) reached", s.maxSessions)
 	}
+	return nil
 }
 
 // Clear removes all sessions and cleans up all resources
 func (s *SessionManager) Clear() error {
-	// This is a simplified approach since Ristretto doesn't provide iteration
-	s.cache.Clear()
-	atomic.StoreInt64(&s.sessionCount, 0)
+	// Ristretto doesn't provide iteration, walk the session index instead
+	s.sessionsMu.Lock()
+	sessions := s.sessions
+	databases := s.databases
+	s.sessions = make(map[string]*lease)
+	s.databases = make(map[string]map[string]*lease)
+	s.sessionsMu.Unlock()
+
+	for sessionId, l := range sessions {
+		// Expired sessions are no longer returned by the cache but still hold a lease
+		state := &SessionState{SessionID: sessionId, lease: l}
+		if item, found := s.cache.Get(sessionId); found {
+			if cached, ok := item.(*SessionState); ok {
+				state = cached
+			}
+		}
 
-	logrus.Info("All sessions cleared")
+		s.cache.Del(sessionId)
+		s.pool.release(l)
+		for _, override := range databases[sessionId] {
+			s.pool.release(override)
+		}
+		s.triggerEvent(SessionRemoved, sessionId, state)
+	}
+	s.cache.Wait()
+
+	logrus.WithField("sessions", len(sessions)).Info("All sessions cleared")
 	return nil
 }
 
 // Size returns the current number of sessions
 func (s *SessionManager) Size() int {
-	return int(atomic.LoadInt64(&s.sessionCount))
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	return len(s.sessions)
+}
+
+// List returns the state of every live session, ordered by session ID
+func (s *SessionManager) List() []*SessionState {
+	s.sessionsMu.Lock()
+	ids := make([]string, 0, len(s.sessions))
+	for sessionId := range s.sessions {
+		ids = append(ids, sessionId)
+	}
+	s.sessionsMu.Unlock()
+	sort.Strings(ids)
+
+	states := make([]*SessionState, 0, len(ids))
+	for _, sessionId := range ids {
+		// Skip sessions removed or expired since the snapshot
+		if state, err := s.GetState(sessionId); err == nil {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// Disconnect removes the session and refuses further calls on it until it would have expired
+func (s *SessionManager) Disconnect(sessionId string) error {
+	if _, err := s.GetState(sessionId); err != nil {
+		return err
+	}
+
+	s.markDisconnected(sessionId)
+	if err := s.Remove(sessionId); err != nil {
+		return err
+	}
+
+	logrus.WithField("session", sessionId).Warn("Session disconnected by operator")
+	return nil
+}
+
+// markDisconnected records the disconnect, forgetting disconnects older than the session TTL
+func (s *SessionManager) markDisconnected(sessionId string) {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	now := time.Now()
+	for id, at := range s.disconnected {
+		if now.Sub(at) > s.defaultTTL {
+			delete(s.disconnected, id)
+		}
+	}
+	s.disconnected[sessionId] = now
+}
+
+// IsDisconnected reports whether an operator disconnected the session
+func (s *SessionManager) IsDisconnected(sessionId string) bool {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	at, found := s.disconnected[sessionId]
+	return found && time.Since(at) <= s.defaultTTL
 }
 
 // GetSessionMetadata retrieves metadata for a session
@@ -394,6 +904,9 @@ func (s *SessionManager) GetSessionMetadata(sessionId string) (map[string]interf
 
 // SetSessionMetadata sets metadata for a session
 func (s *SessionManager) SetSessionMetadata(sessionId string, key string, value interface{}) error {
+	lock := s.lockSession(sessionId)
+	defer lock.Unlock()
+
 	item, found := s.cache.Get(sessionId)
 	if !found {
 		return fmt.Errorf("session not found: %s", sessionId)
@@ -403,6 +916,9 @@ func (s *SessionManager) SetSessionMetadata(sessionId string, key string, value
 	if !ok {
 		return fmt.Errorf("invalid session data for: %s", sessionId)
 	}
+	if !s.holds(sessionId, state.lease) {
+		return fmt.Errorf("session not found: %s", sessionId)
+	}
 
 	// Create a copy and update metadata
 	updatedState := *state
@@ -441,7 +957,7 @@ func (s *SessionManager) Close() error {
 	// Clear all sessions
 	s.Clear()
 
-	// Close the cache
+	// Close the cache, any entry left over is released by onEvict
 	s.cache.Close()
 
 	return nil
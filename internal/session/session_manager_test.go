@@ -0,0 +1,551 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/milvus-io/milvus/client/v2/milvusclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClients replaces the Milvus client constructor and records closed clients
+type fakeClients struct {
+	mu      sync.Mutex
+	created []*milvusclient.Client
+	closed  map[*milvusclient.Client]int
+	broken  map[*milvusclient.Client]bool
+	err     error
+}
+
+func useFakeClients(t *testing.T) *fakeClients {
+	t.Helper()
+	fake := &fakeClients{
+		closed: make(map[*milvusclient.Client]int),
+		broken: make(map[*milvusclient.Client]bool),
+	}
+
+	origNew, origClose, origCheck := newClient, closeClient, checkClient
+	newClient = func(ctx context.Context, config *milvusclient.ClientConfig) (*milvusclient.Client, error) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		if fake.err != nil {
+			return nil, fake.err
+		}
+		client := new(milvusclient.Client)
+		fake.created = append(fake.created, client)
+		return client, nil
+	}
+	closeClient = func(client *milvusclient.Client) error {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		fake.closed[client]++
+		return nil
+	}
+	checkClient = func(ctx context.Context, client *milvusclient.Client) error {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		if fake.broken[client] {
+			return errors.New("connection reset")
+		}
+		return nil
+	}
+	t.Cleanup(func() {
+		newClient, closeClient, checkClient = origNew, origClose, origCheck
+	})
+	return fake
+}
+
+// breakServer makes health checks of client fail and new connections fail with err
+func (f *fakeClients) breakServer(client *milvusclient.Client, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.broken[client] = true
+	f.err = err
+}
+
+func (f *fakeClients) restoreServer() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = nil
+}
+
+func (f *fakeClients) closeCount(client *milvusclient.Client) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed[client]
+}
+
+func (f *fakeClients) client(i int) *milvusclient.Client {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.created[i]
+}
+
+func (f *fakeClients) createdCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.created)
+}
+
+func newTestManager(t *testing.T, maxSessions int, ttl time.Duration) *SessionManager {
+	t.Helper()
+	sm := NewSessionManagerWithOptions(Options{
+		MaxSessions:    maxSessions,
+		DefaultTTL:     ttl,
+		MaxConnections: 10,
+		NumCounters:    1000,
+		MaxCost:        100,
+		BufferItems:    64,
+	})
+	t.Cleanup(func() { sm.Close() })
+	return sm
+}
+
+// recordEvents collects the sessions reported for event
+func recordEvents(sm *SessionManager, event SessionEvent) chan string {
+	ch := make(chan string, 16)
+	sm.AddEventCallback(func(e SessionEvent, sessionID string, state *SessionState) {
+		if e == event {
+			ch <- sessionID
+		}
+	})
+	return ch
+}
+
+func waitEvent(t *testing.T, ch chan string) string {
+	t.Helper()
+	select {
+	case id := <-ch:
+		return id
+	case <-time.After(15 * time.Second):
+		t.Fatal("timed out waiting for session event")
+		return ""
+	}
+}
+
+var (
+	testConfig  = &ConnConfig{Address: "localhost:19530"}
+	otherConfig = &ConnConfig{Address: "localhost:19531"}
+)
+
+func TestSetReplacesExistingSession(t *testing.T) {
+	fake := useFakeClients(t)
+	sm := newTestManager(t, 10, time.Hour)
+
+	require.NoError(t, sm.Set("s1", testConfig))
+	require.NoError(t, sm.Set("s1", otherConfig))
+	assert.Equal(t, 1, sm.Size(), "re-setting a session must not count it twice")
+
+	assert.Equal(t, 1, fake.closeCount(fake.client(0)), "the replaced client is closed")
+	assert.Equal(t, 0, fake.closeCount(fake.client(1)))
+
+	client, err := sm.Get("s1")
+	require.NoError(t, err)
+	assert.Same(t, fake.client(1), client)
+}
+
+func TestFailedSetKeepsSession(t *testing.T) {
+	fake := useFakeClients(t)
+	sm := newTestManager(t, 10, time.Hour)
+
+	require.NoError(t, sm.Set("s1", testConfig))
+	fake.err = errors.New("connection refused")
+	assert.Error(t, sm.Set("s1", otherConfig))
+
+	client, err := sm.Get("s1")
+	require.NoError(t, err)
+	assert.Same(t, fake.client(0), client)
+	assert.Equal(t, 0, fake.closeCount(client))
+	assert.Equal(t, 1, sm.Size())
+}
+
+func TestMaxSessions(t *testing.T) {
+	useFakeClients(t)
+	sm := newTestManager(t, 2, time.Hour)
+
+	require.NoError(t, sm.Set("s1", testConfig))
+	require.NoError(t, sm.Set("s2", testConfig))
+	assert.Error(t, sm.Set("s3", testConfig))
+	assert.NoError(t, sm.Set("s1", testConfig), "existing sessions can reconnect at the limit")
+
+	require.NoError(t, sm.Remove("s2"))
+	assert.NoError(t, sm.Set("s3", testConfig))
+	assert.Equal(t, 2, sm.Size())
+}
+
+func TestRemove(t *testing.T) {
+	fake := useFakeClients(t)
+	sm := newTestManager(t, 10, time.Hour)
+	removed := recordEvents(sm, SessionRemoved)
+
+	require.NoError(t, sm.Set("s1", testConfig))
+	require.NoError(t, sm.Remove("s1"))
+	assert.Equal(t, "s1", waitEvent(t, removed))
+	assert.Equal(t, 1, fake.closeCount(fake.client(0)))
+	assert.Equal(t, 0, sm.Size())
+
+	assert.Error(t, sm.Remove("s1"))
+	_, err := sm.Get("s1")
+	assert.Error(t, err)
+}
+
+func TestClearClosesClients(t *testing.T) {
+	fake := useFakeClients(t)
+	sm := newTestManager(t, 10, time.Hour)
+	removed := recordEvents(sm, SessionRemoved)
+
+	require.NoError(t, sm.Set("s1", testConfig))
+	require.NoError(t, sm.Set("s2", otherConfig))
+	require.NoError(t, sm.Clear())
+
+	assert.ElementsMatch(t, []string{"s1", "s2"}, []string{waitEvent(t, removed), waitEvent(t, removed)})
+	assert.Equal(t, 1, fake.closeCount(fake.client(0)))
+	assert.Equal(t, 1, fake.closeCount(fake.client(1)))
+	assert.Equal(t, 0, sm.Size())
+
+	_, err := sm.Get("s1")
+	assert.Error(t, err)
+}
+
+func TestExpiry(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for the Ristretto TTL cleanup")
+	}
+	fake := useFakeClients(t)
+	sm := newTestManager(t, 10, 100*time.Millisecond)
+	expired := recordEvents(sm, SessionExpired)
+
+	require.NoError(t, sm.Set("s1", testConfig))
+	assert.Equal(t, "s1", waitEvent(t, expired))
+	assert.Equal(t, 1, fake.closeCount(fake.client(0)))
+	assert.Equal(t, 0, sm.Size())
+
+	assert.Error(t, sm.Remove("s1"), "expired sessions are gone")
+	assert.NoError(t, sm.Set("s1", testConfig))
+}
+
+func TestCloseReleasesSessions(t *testing.T) {
+	fake := useFakeClients(t)
+	sm := NewSessionManagerWithOptions(Options{
+		MaxSessions: 10,
+		DefaultTTL:  time.Hour,
+		NumCounters: 1000,
+		MaxCost:     100,
+		BufferItems: 64,
+	})
+
+	require.NoError(t, sm.Set("s1", testConfig))
+	require.NoError(t, sm.Close())
+	assert.Equal(t, 1, fake.closeCount(fake.client(0)))
+	assert.Equal(t, 0, sm.Size())
+}
+
+func TestSessionsShareClients(t *testing.T) {
+	fake := useFakeClients(t)
+	sm := newTestManager(t, 10, time.Hour)
+
+	require.NoError(t, sm.Set("s1", testConfig))
+	require.NoError(t, sm.Set("s2", &ConnConfig{Address: "localhost:19530", DBName: "default", Profile: "local"}))
+	require.NoError(t, sm.Set("s3", otherConfig))
+	assert.Equal(t, 2, fake.createdCount(), "same address, credentials and database share a client")
+	assert.Equal(t, 2, sm.pool.size())
+
+	c1, err := sm.Get("s1")
+	require.NoError(t, err)
+	c2, err := sm.Get("s2")
+	require.NoError(t, err)
+	assert.Same(t, c1, c2)
+
+	require.NoError(t, sm.Remove("s1"))
+	assert.Equal(t, 0, fake.closeCount(c1), "the client is still used by s2")
+	require.NoError(t, sm.Remove("s2"))
+	assert.Equal(t, 1, fake.closeCount(c1))
+	assert.Equal(t, 1, sm.pool.size())
+}
+
+func TestUseDatabase(t *testing.T) {
+	fake := useFakeClients(t)
+	sm := newTestManager(t, 10, time.Hour)
+
+	require.NoError(t, sm.Set("s1", testConfig))
+	require.NoError(t, sm.Set("s2", testConfig))
+	shared := fake.client(0)
+
+	require.NoError(t, sm.UseDatabase("s1", "analytics"))
+	state, err := sm.GetState("s1")
+	require.NoError(t, err)
+	assert.Equal(t, "analytics", state.ConnConfig.DBName)
+	assert.Same(t, fake.client(1), state.Client)
+	assert.Empty(t, testConfig.DBName, "the original config is not modified")
+
+	client, err := sm.Get("s2")
+	require.NoError(t, err)
+	assert.Same(t, shared, client, "other sessions keep their database")
+
+	require.NoError(t, sm.UseDatabase("s2", "analytics"))
+	assert.Equal(t, 1, fake.closeCount(shared), "the unused client is closed")
+	assert.Equal(t, 1, sm.pool.size())
+
+	assert.Error(t, sm.UseDatabase("missing", "analytics"))
+}
+
+func TestConcurrentUpdatesKeepLease(t *testing.T) {
+	fake := useFakeClients(t)
+	sm := newTestManager(t, 10, time.Hour)
+	require.NoError(t, sm.Set("s1", testConfig))
+
+	const calls = 100
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			_, err := sm.Get("s1")
+			assert.NoError(t, err)
+		}()
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, sm.SetSessionMetadata("s1", fmt.Sprintf("key%d", i), i))
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, sm.UseDatabase("s1", fmt.Sprintf("db%d", i%2)))
+		}(i)
+	}
+	wg.Wait()
+
+	state, err := sm.GetState("s1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(calls), state.AccessCount)
+	assert.Len(t, state.Metadata, calls)
+
+	// A stale update writing back a released lease would keep its client open
+	require.NoError(t, sm.Remove("s1"))
+	assert.Equal(t, 0, sm.pool.size())
+	for i := 0; i < fake.createdCount(); i++ {
+		assert.Equal(t, 1, fake.closeCount(fake.client(i)))
+	}
+}
+
+func TestMaxConnections(t *testing.T) {
+	useFakeClients(t)
+	sm := NewSessionManagerWithOptions(Options{
+		MaxSessions:    10,
+		DefaultTTL:     time.Hour,
+		MaxConnections: 1,
+		NumCounters:    1000,
+		MaxCost:        100,
+		BufferItems:    64,
+	})
+	t.Cleanup(func() { sm.Close() })
+
+	require.NoError(t, sm.Set("s1", testConfig))
+	require.NoError(t, sm.Set("s2", testConfig), "shared clients do not count")
+	assert.Error(t, sm.Set("s3", otherConfig))
+	assert.Error(t, sm.UseDatabase("s1", "analytics"))
+	assert.Equal(t, 2, sm.Size())
+}
+
+func TestListAndDisconnect(t *testing.T) {
+	fake := useFakeClients(t)
+	sm := newTestManager(t, 10, time.Hour)
+
+	require.NoError(t, sm.Set("s2", testConfig))
+	require.NoError(t, sm.Set("s1", &ConnConfig{Address: "localhost:19530", DBName: "analytics"}))
+
+	states := sm.List()
+	require.Len(t, states, 2)
+	assert.Equal(t, "s1", states[0].SessionID)
+	assert.Equal(t, "analytics", states[0].Info().Database)
+	assert.Equal(t, "default", states[1].Info().Database)
+
+	require.NoError(t, sm.Disconnect("s1"))
+	assert.True(t, sm.IsDisconnected("s1"))
+	assert.False(t, sm.IsDisconnected("s2"))
+	assert.Equal(t, 1, fake.closeCount(fake.client(1)))
+	assert.Len(t, sm.List(), 1)
+
+	assert.Error(t, sm.Disconnect("missing"))
+}
+
+func TestGetForDatabase(t *testing.T) {
+	fake := useFakeClients(t)
+	sm := newTestManager(t, 10, time.Hour)
+
+	require.NoError(t, sm.Set("s1", testConfig))
+	current := fake.client(0)
+
+	client, err := sm.GetForDatabase("s1", "")
+	require.NoError(t, err)
+	assert.Same(t, current, client)
+	client, err = sm.GetForDatabase("s1", "default")
+	require.NoError(t, err)
+	assert.Same(t, current, client, "the current database needs no extra client")
+
+	analytics, err := sm.GetForDatabase("s1", "analytics")
+	require.NoError(t, err)
+	assert.NotSame(t, current, analytics)
+	client, err = sm.GetForDatabase("s1", "analytics")
+	require.NoError(t, err)
+	assert.Same(t, analytics, client, "override clients are kept for later calls")
+	assert.Equal(t, 2, fake.createdCount())
+
+	state, err := sm.GetState("s1")
+	require.NoError(t, err)
+	assert.Empty(t, state.ConnConfig.DBName, "the session does not switch database")
+
+	require.NoError(t, sm.Remove("s1"))
+	assert.Equal(t, 1, fake.closeCount(analytics), "override clients are released with the session")
+	assert.Equal(t, 0, sm.pool.size())
+
+	_, err = sm.GetForDatabase("s1", "analytics")
+	assert.Error(t, err)
+}
+
+func TestHealthCheckReconnects(t *testing.T) {
+	fake := useFakeClients(t)
+	origGrace := replacedClientGrace
+	replacedClientGrace = 200 * time.Millisecond
+	t.Cleanup(func() { replacedClientGrace = origGrace })
+	sm := NewSessionManagerWithOptions(Options{
+		MaxSessions:         10,
+		DefaultTTL:          time.Hour,
+		HealthCheckInterval: 10 * time.Millisecond,
+		NumCounters:         1000,
+		MaxCost:             100,
+		BufferItems:         64,
+	})
+	t.Cleanup(func() { sm.Close() })
+
+	require.NoError(t, sm.Set("s1", &ConnConfig{Address: "localhost:19530", DBName: "analytics"}))
+	broken := fake.client(0)
+	fake.breakServer(broken, errors.New("connection refused"))
+
+	require.Eventually(t, func() bool {
+		_, err := sm.Get("s1")
+		return errors.Is(err, ErrUnavailable)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	fake.restoreServer()
+	require.Eventually(t, func() bool {
+		client, err := sm.Get("s1")
+		return err == nil && client != broken
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, fake.closeCount(broken), "calls still running on the broken client may finish")
+	require.Eventually(t, func() bool { return fake.closeCount(broken) == 1 },
+		5*time.Second, 10*time.Millisecond, "the broken client is closed after the grace period")
+
+	state, err := sm.GetState("s1")
+	require.NoError(t, err)
+	assert.Equal(t, "analytics", state.ConnConfig.DBName, "the reconnected session keeps its database")
+	assert.NotSame(t, broken, state.Client)
+}
+
+func TestHealthCheckToleratesSlowAnswer(t *testing.T) {
+	fake := useFakeClients(t)
+	var mu sync.Mutex
+	checks := 0
+	origCheck := checkClient
+	checkClient = func(ctx context.Context, client *milvusclient.Client) error {
+		mu.Lock()
+		defer mu.Unlock()
+		checks++
+		if checks == 1 {
+			return context.DeadlineExceeded
+		}
+		return nil
+	}
+	t.Cleanup(func() { checkClient = origCheck })
+
+	sm := NewSessionManagerWithOptions(Options{
+		MaxSessions:         10,
+		DefaultTTL:          time.Hour,
+		HealthCheckInterval: 10 * time.Millisecond,
+		NumCounters:         1000,
+		MaxCost:             100,
+		BufferItems:         64,
+	})
+	t.Cleanup(func() { sm.Close() })
+	require.NoError(t, sm.Set("s1", testConfig))
+
+	_, err := sm.Get("s1")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return checks > healthCheckFailures
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = sm.Get("s1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fake.createdCount(), "a single failed check does not reconnect")
+}
+
+func TestProbeProfiles(t *testing.T) {
+	fake := useFakeClients(t)
+	require.NoError(t, SetProfiles(map[string]ConnConfig{"prod": *testConfig}, "", true))
+	t.Cleanup(func() { SetProfiles(nil, "", true) })
+
+	assert.Empty(t, ProbeProfiles(context.Background()))
+	assert.Equal(t, 1, fake.closeCount(fake.client(0)), "probe clients are not kept open")
+
+	fake.breakServer(fake.client(0), errors.New("connection refused"))
+	failed := ProbeProfiles(context.Background())
+	assert.Contains(t, failed, "prod")
+}
+
+func TestProbeProfilesUsesPoolHealth(t *testing.T) {
+	fake := useFakeClients(t)
+	require.NoError(t, SetProfiles(map[string]ConnConfig{"prod": *testConfig}, "", true))
+	t.Cleanup(func() { SetProfiles(nil, "", true) })
+
+	sm := NewSessionManagerWithOptions(Options{
+		MaxSessions:         10,
+		DefaultTTL:          time.Hour,
+		HealthCheckInterval: 10 * time.Millisecond,
+		NumCounters:         1000,
+		MaxCost:             100,
+		BufferItems:         64,
+	})
+	t.Cleanup(func() { sm.Close() })
+
+	config, err := GetProfile("prod")
+	require.NoError(t, err)
+	require.NoError(t, sm.Set("s1", config))
+
+	assert.Empty(t, probeProfiles(context.Background(), sm.pool))
+	assert.Equal(t, 1, fake.createdCount(), "a pooled profile is not dialed again")
+
+	fake.breakServer(fake.client(0), errors.New("connection refused"))
+	require.Eventually(t, func() bool {
+		return errors.Is(probeProfiles(context.Background(), sm.pool)["prod"], ErrUnavailable)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestProbeProfilesDeadline(t *testing.T) {
+	fake := useFakeClients(t)
+	require.NoError(t, SetProfiles(map[string]ConnConfig{"prod": *testConfig}, "", true))
+	t.Cleanup(func() { SetProfiles(nil, "", true) })
+
+	block := make(chan struct{})
+	origCheck := checkClient
+	checkClient = func(ctx context.Context, client *milvusclient.Client) error {
+		<-block
+		return nil
+	}
+	t.Cleanup(func() { checkClient = origCheck })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	failed := probeProfiles(ctx, nil)
+	assert.ErrorIs(t, failed["prod"], context.DeadlineExceeded, "a probe ignoring the deadline does not hold up the result")
+
+	close(block)
+	require.Eventually(t, func() bool { return fake.closeCount(fake.client(0)) == 1 },
+		5*time.Second, 10*time.Millisecond, "the late probe still closes its client")
+}
@@ -0,0 +1,465 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tailabs/mcp-milvus/internal/session/store"
+
+	"github.com/milvus-io/milvus/client/v2/milvusclient"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// storeTimeout bounds every round trip to the session store
+	storeTimeout = 5 * time.Second
+
+	// sessionPrefix prefixes the stored sessions, so they can be listed apart from other keys
+	sessionPrefix = "session/"
+	// disconnectedPrefix marks sessions disconnected by an operator in the store
+	disconnectedPrefix = "disconnected/"
+)
+
+// errSessionNotFound tells a session missing from the store apart from a failing store
+var errSessionNotFound = errors.New("session not found")
+
+// Fields of a stored session, each one is updated on its own so replicas
+// changing different fields of the same session never lose each other's changes
+const (
+	fieldRecord       = "record"
+	fieldDatabase     = "database"
+	fieldLastAccessed = "last_accessed"
+	fieldAccessCount  = "access_count"
+	// fieldMetadataPrefix prefixes one field per metadata key
+	fieldMetadataPrefix = "metadata/"
+)
+
+// sessionRecord is the part of a stored session written once when it connects
+// Profile sessions only keep the profile name and are resolved against the profiles
+// of the replica loading them, custom connections keep their credentials sealed
+type sessionRecord struct {
+	SessionID   string      `json:"session_id"`
+	Principal   string      `json:"principal,omitempty"`
+	Profile     string      `json:"profile,omitempty"`
+	ConnConfig  *ConnConfig `json:"conn_config,omitempty"`
+	Credentials []byte      `json:"credentials,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// SharedSessionManager keeps sessions in an external store shared by all replicas
+// Each replica holds the Milvus clients of the sessions it served in a local
+// SessionManager and recreates them lazily from the stored connection config
+type SharedSessionManager struct {
+	local  *SessionManager
+	store  store.Store
+	ttl    time.Duration
+	sealer *sealer
+}
+
+// NewSharedSessionManager persists sessions to st, using local for the Milvus clients
+// key encrypts the credentials of custom connections, without it only profile
+// sessions and custom connections without credentials can be shared
+func NewSharedSessionManager(local *SessionManager, st store.Store, key []byte) (*SharedSessionManager, error) {
+	sealer, err := newSealer(key)
+	if err != nil {
+		return nil, err
+	}
+	return &SharedSessionManager{
+		local:  local,
+		store:  st,
+		ttl:    local.defaultTTL,
+		sealer: sealer,
+	}, nil
+}
+
+// encode returns the stored fields of a newly connected session
+func (s *SharedSessionManager) encode(sessionId string, config *ConnConfig, now time.Time) (map[string][]byte, error) {
+	record := sessionRecord{
+		SessionID: sessionId,
+		Principal: principalSubject(sessionId),
+		Profile:   config.Profile,
+		CreatedAt: now,
+	}
+	if config.Profile == "" {
+		stored := *config
+		stored.Token, stored.APIKey, stored.DBName = "", "", ""
+		record.ConnConfig = &stored
+
+		if creds := (secretFields{Token: config.Token, APIKey: config.APIKey}); creds != (secretFields{}) {
+			if s.sealer == nil {
+				return nil, fmt.Errorf("custom connections with credentials cannot be shared between replicas without a session store encryption key, connect with a profile instead")
+			}
+			sealed, err := s.sealer.seal(sessionId, creds)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encrypt credentials of session %s: %w", sessionId, err)
+			}
+			record.Credentials = sealed
+		}
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode session %s: %w", sessionId, err)
+	}
+	return map[string][]byte{
+		fieldRecord:       data,
+		fieldDatabase:     []byte(config.DBName),
+		fieldLastAccessed: []byte(now.Format(time.RFC3339Nano)),
+		fieldAccessCount:  []byte("0"),
+	}, nil
+}
+
+// decode rebuilds the session state from its stored fields, without a client
+// Metadata values round-trip through JSON, so times become strings and numbers float64
+func (s *SharedSessionManager) decode(sessionId string, fields map[string][]byte) (*SessionState, error) {
+	var record sessionRecord
+	if err := json.Unmarshal(fields[fieldRecord], &record); err != nil || record.SessionID != sessionId {
+		return nil, fmt.Errorf("invalid session data for: %s", sessionId)
+	}
+
+	config, err := s.connection(&record)
+	if err != nil {
+		return nil, err
+	}
+	config.DBName = string(fields[fieldDatabase])
+
+	state := &SessionState{
+		SessionID:  record.SessionID,
+		Principal:  record.Principal,
+		ConnConfig: config,
+		CreatedAt:  record.CreatedAt,
+		Metadata:   make(map[string]interface{}),
+	}
+	state.LastAccessed, _ = time.Parse(time.RFC3339Nano, string(fields[fieldLastAccessed]))
+	state.AccessCount, _ = strconv.ParseInt(string(fields[fieldAccessCount]), 10, 64)
+	for field, data := range fields {
+		key, ok := strings.CutPrefix(field, fieldMetadataPrefix)
+		if !ok {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err == nil {
+			state.Metadata[key] = value
+		}
+	}
+	return state, nil
+}
+
+// connection returns the connection config of a stored session, with its credentials
+func (s *SharedSessionManager) connection(record *sessionRecord) (*ConnConfig, error) {
+	if record.Profile != "" {
+		return GetProfile(record.Profile)
+	}
+	if record.ConnConfig == nil {
+		return nil, fmt.Errorf("invalid session data for: %s", record.SessionID)
+	}
+
+	config := *record.ConnConfig
+	if record.Credentials != nil {
+		if s.sealer == nil {
+			return nil, fmt.Errorf("session %s has encrypted credentials but no session store encryption key is set", record.SessionID)
+		}
+		creds, err := s.sealer.open(record.SessionID, record.Credentials)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credentials for session %s: %w", record.SessionID, err)
+		}
+		config.Token = creds.Token
+		config.APIKey = creds.APIKey
+	}
+	return &config, nil
+}
+
+func (s *SharedSessionManager) load(sessionId string) (*SessionState, error) {
+	if sessionId == "" {
+		return nil, fmt.Errorf("session ID cannot be empty")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	fields, err := s.store.GetHash(ctx, sessionPrefix+sessionId)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", errSessionNotFound, sessionId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session %s: %w", sessionId, err)
+	}
+	return s.decode(sessionId, fields)
+}
+
+// update changes fields of a stored session and refreshes its TTL, returning the updated state
+func (s *SharedSessionManager) update(sessionId string, set map[string][]byte, incr map[string]int64) (*SessionState, error) {
+	if sessionId == "" {
+		return nil, fmt.Errorf("session ID cannot be empty")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	fields, err := s.store.UpdateHash(ctx, sessionPrefix+sessionId, set, incr, s.ttl)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", errSessionNotFound, sessionId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update session %s: %w", sessionId, err)
+	}
+	return s.decode(sessionId, fields)
+}
+
+// attach makes sure this replica holds a client for the stored connection,
+// recreating it if the session was created or switched on another replica
+func (s *SharedSessionManager) attach(shared *SessionState) error {
+	// Another principal presenting the session ID on this replica is rejected like on the original one
+	if shared.Principal != "" && principalSubject(shared.SessionID) != shared.Principal {
+		return fmt.Errorf("session %s belongs to another principal", shared.SessionID)
+	}
+
+	if state, err := s.local.GetState(shared.SessionID); err == nil && *state.ConnConfig == *shared.ConnConfig {
+		return nil
+	}
+
+	config := *shared.ConnConfig
+	if err := s.local.Set(shared.SessionID, &config); err != nil {
+		return err
+	}
+	logrus.WithFields(logrus.Fields{
+		"session":  shared.SessionID,
+		"address":  config.Address,
+		"database": config.DBName,
+	}).Info("Session restored from store")
+	return nil
+}
+
+// Get retrieves the Milvus client for the specified session
+func (s *SharedSessionManager) Get(sessionId string) (*milvusclient.Client, error) {
+	state, err := s.Access(sessionId)
+	if err != nil {
+		return nil, err
+	}
+	return state.Client, nil
+}
+
+// Access returns the stored session state with this replica's client, refreshing
+// the stored TTL and access statistics in the same round trip
+func (s *SharedSessionManager) Access(sessionId string) (*SessionState, error) {
+	state, err := s.update(sessionId,
+		map[string][]byte{fieldLastAccessed: []byte(time.Now().Format(time.RFC3339Nano))},
+		map[string]int64{fieldAccessCount: 1},
+	)
+	if err != nil {
+		// A store that does not answer keeps the local client, only a session
+		// removed or expired in the store releases it
+		if errors.Is(err, errSessionNotFound) {
+			s.dropLocal(sessionId)
+		}
+		return nil, err
+	}
+	if err := s.attach(state); err != nil {
+		return nil, err
+	}
+
+	// Keep the local entry alive and fire SessionAccessed
+	local, err := s.local.Access(sessionId)
+	if err != nil {
+		return nil, err
+	}
+	state.Client = local.Client
+	return state, nil
+}
+
+// GetForDatabase returns a client for another database without switching the session
+func (s *SharedSessionManager) GetForDatabase(sessionId string, dbName string) (*milvusclient.Client, error) {
+	// Tool calls have attached the session on this replica already, see CallState
+	if _, err := s.local.GetState(sessionId); err != nil {
+		if _, err := s.Access(sessionId); err != nil {
+			return nil, err
+		}
+	}
+	return s.local.GetForDatabase(sessionId, dbName)
+}
+
+// GetState retrieves the complete session state
+// The client is only set if this replica already holds one for the session
+func (s *SharedSessionManager) GetState(sessionId string) (*SessionState, error) {
+	state, err := s.load(sessionId)
+	if err != nil {
+		return nil, err
+	}
+	if local, err := s.local.GetState(sessionId); err == nil && *local.ConnConfig == *state.ConnConfig {
+		state.Client = local.Client
+	}
+	return state, nil
+}
+
+// Set connects the session on this replica and persists its connection
+func (s *SharedSessionManager) Set(sessionId string, config *ConnConfig) error {
+	if config == nil {
+		return fmt.Errorf("connection config cannot be nil")
+	}
+	fields, err := s.encode(sessionId, config, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := s.local.Set(sessionId, config); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := s.store.SetHash(ctx, sessionPrefix+sessionId, fields, s.ttl); err != nil {
+		s.local.Remove(sessionId)
+		return fmt.Errorf("failed to save session %s: %w", sessionId, err)
+	}
+	return nil
+}
+
+// UseDatabase switches the session to another database on every replica
+func (s *SharedSessionManager) UseDatabase(sessionId string, dbName string) error {
+	state, err := s.load(sessionId)
+	if err != nil {
+		return err
+	}
+	if err := s.attach(state); err != nil {
+		return err
+	}
+	if err := s.local.UseDatabase(sessionId, dbName); err != nil {
+		return err
+	}
+
+	_, err = s.update(sessionId, map[string][]byte{fieldDatabase: []byte(dbName)}, nil)
+	return err
+}
+
+// Remove deletes the session from the store and releases its client on this replica
+// Other replicas release theirs when they next see the session missing or it expires locally
+func (s *SharedSessionManager) Remove(sessionId string) error {
+	if sessionId == "" {
+		return fmt.Errorf("session ID cannot be empty")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := s.store.Delete(ctx, sessionPrefix+sessionId); err != nil {
+		return fmt.Errorf("failed to delete session %s: %w", sessionId, err)
+	}
+	s.dropLocal(sessionId)
+	return nil
+}
+
+// dropLocal releases the local client of a session that no longer exists in the store
+func (s *SharedSessionManager) dropLocal(sessionId string) {
+	if _, err := s.local.GetState(sessionId); err == nil {
+		s.local.Remove(sessionId)
+	}
+}
+
+// Clear releases the clients held by this replica, the stored sessions stay
+// available to the other replicas
+func (s *SharedSessionManager) Clear() error {
+	return s.local.Clear()
+}
+
+// Size returns the number of sessions served by this replica, unlike List it does not ask the store
+func (s *SharedSessionManager) Size() int {
+	return s.local.Size()
+}
+
+// List returns the stored state of the sessions of all replicas
+func (s *SharedSessionManager) List() []*SessionState {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	keys, err := s.store.Keys(ctx, sessionPrefix)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to list stored sessions")
+		return nil
+	}
+
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		ids = append(ids, strings.TrimPrefix(key, sessionPrefix))
+	}
+	sort.Strings(ids)
+
+	states := make([]*SessionState, 0, len(ids))
+	for _, sessionId := range ids {
+		// Skip sessions removed or expired since the scan
+		if state, err := s.GetState(sessionId); err == nil {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// Disconnect removes the session from the store and refuses its calls on every replica
+func (s *SharedSessionManager) Disconnect(sessionId string) error {
+	if _, err := s.load(sessionId); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := s.store.Set(ctx, disconnectedPrefix+sessionId, []byte("1"), s.ttl); err != nil {
+		return fmt.Errorf("failed to disconnect session %s: %w", sessionId, err)
+	}
+	s.local.markDisconnected(sessionId)
+	if err := s.Remove(sessionId); err != nil {
+		return err
+	}
+
+	logrus.WithField("session", sessionId).Warn("Session disconnected by operator")
+	return nil
+}
+
+// IsDisconnected reports whether an operator disconnected the session on any replica
+func (s *SharedSessionManager) IsDisconnected(sessionId string) bool {
+	if s.local.IsDisconnected(sessionId) {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	_, err := s.store.Get(ctx, disconnectedPrefix+sessionId)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		logrus.WithFields(logrus.Fields{
+			"session": sessionId,
+			"error":   err,
+		}).Warn("Failed to check session disconnect")
+	}
+	return err == nil
+}
+
+// Close releases the local clients and closes the store
+func (s *SharedSessionManager) Close() error {
+	if err := s.local.Close(); err != nil {
+		return err
+	}
+	return s.store.Close()
+}
+
+// AddEventCallback adds a callback for the session events of this replica
+func (s *SharedSessionManager) AddEventCallback(callback SessionEventCallback) {
+	s.local.AddEventCallback(callback)
+}
+
+// GetSessionMetadata retrieves metadata for a session
+func (s *SharedSessionManager) GetSessionMetadata(sessionId string) (map[string]interface{}, error) {
+	state, err := s.load(sessionId)
+	if err != nil {
+		return nil, err
+	}
+	return state.Metadata, nil
+}
+
+// SetSessionMetadata sets metadata for a session
+func (s *SharedSessionManager) SetSessionMetadata(sessionId string, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata %s: %w", key, err)
+	}
+	_, err = s.update(sessionId, map[string][]byte{fieldMetadataPrefix + key: data}, nil)
+	return err
+}
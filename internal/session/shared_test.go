@@ -0,0 +1,267 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tailabs/mcp-milvus/internal/auth"
+	"github.com/tailabs/mcp-milvus/internal/session/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReplicas returns two shared session managers backed by the same store
+func newReplicas(t *testing.T) (*SharedSessionManager, *SharedSessionManager) {
+	t.Helper()
+	return newReplicasWithKey(t, nil)
+}
+
+// newReplicasWithKey returns two shared session managers sealing credentials with key
+func newReplicasWithKey(t *testing.T, key []byte) (*SharedSessionManager, *SharedSessionManager) {
+	t.Helper()
+	st := store.NewMemory()
+	a, err := NewSharedSessionManager(newTestManager(t, 10, time.Hour), st, key)
+	require.NoError(t, err)
+	b, err := NewSharedSessionManager(newTestManager(t, 10, time.Hour), st, key)
+	require.NoError(t, err)
+	return a, b
+}
+
+func TestSharedSessionAcrossReplicas(t *testing.T) {
+	fake := useFakeClients(t)
+	a, b := newReplicas(t)
+
+	require.NoError(t, a.Set("s1", &ConnConfig{Address: "localhost:19530", DBName: "default"}))
+	require.NoError(t, a.SetSessionMetadata("s1", "client_type", "mcp_client"))
+	assert.Equal(t, 0, b.Size())
+
+	// The other replica recreates the client lazily from the stored config
+	client, err := b.Get("s1")
+	require.NoError(t, err)
+	assert.NotNil(t, client)
+	assert.Equal(t, 1, b.Size())
+	assert.Equal(t, 2, fake.createdCount())
+
+	metadata, err := b.GetSessionMetadata("s1")
+	require.NoError(t, err)
+	assert.Equal(t, "mcp_client", metadata["client_type"])
+
+	// Switching the database on one replica is picked up by the other
+	require.NoError(t, b.UseDatabase("s1", "analytics"))
+	state, err := a.GetState("s1")
+	require.NoError(t, err)
+	assert.Equal(t, "analytics", state.ConnConfig.DBName)
+	assert.Nil(t, state.Client, "replica a still holds the client of the old database")
+
+	_, err = a.Get("s1")
+	require.NoError(t, err)
+	local, err := a.local.GetState("s1")
+	require.NoError(t, err)
+	assert.Equal(t, "analytics", local.ConnConfig.DBName)
+
+	state, err = b.GetState("s1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), state.AccessCount)
+}
+
+func TestSharedSessionRemove(t *testing.T) {
+	fake := useFakeClients(t)
+	a, b := newReplicas(t)
+
+	require.NoError(t, a.Set("s1", testConfig))
+	_, err := b.Get("s1")
+	require.NoError(t, err)
+
+	require.NoError(t, a.Remove("s1"))
+	assert.Equal(t, 0, a.Size())
+	assert.Equal(t, 1, fake.closeCount(fake.client(0)))
+
+	// Replica b releases its client once it notices the session is gone
+	_, err = b.Get("s1")
+	assert.Error(t, err)
+	assert.Equal(t, 0, b.Size())
+	assert.Equal(t, 1, fake.closeCount(fake.client(1)))
+}
+
+func TestSharedSessionClearKeepsStore(t *testing.T) {
+	useFakeClients(t)
+	a, b := newReplicas(t)
+
+	require.NoError(t, a.Set("s1", testConfig))
+	require.NoError(t, a.Clear())
+	assert.Equal(t, 0, a.Size())
+
+	_, err := b.Get("s1")
+	assert.NoError(t, err, "shutting down one replica must not end the session")
+}
+
+func TestSharedSessionPrincipal(t *testing.T) {
+	useFakeClients(t)
+	a, b := newReplicas(t)
+	t.Cleanup(func() { unbindPrincipal("s-owned") })
+
+	require.NoError(t, BindPrincipal("s-owned", &auth.Principal{Subject: "alice"}))
+	require.NoError(t, a.Set("s-owned", testConfig))
+
+	// Simulate replica b seeing the session ID with other credentials
+	unbindPrincipal("s-owned")
+	require.NoError(t, BindPrincipal("s-owned", &auth.Principal{Subject: "mallory"}))
+	_, err := b.Get("s-owned")
+	assert.Error(t, err)
+}
+
+func TestSharedSessionDisconnect(t *testing.T) {
+	useFakeClients(t)
+	a, b := newReplicas(t)
+
+	require.NoError(t, a.Set("s1", testConfig))
+	require.NoError(t, b.Disconnect("s1"))
+	assert.True(t, a.IsDisconnected("s1"), "disconnects apply to every replica")
+	_, err := a.Get("s1")
+	assert.Error(t, err)
+}
+
+// flakyStore fails session updates with err while it is set
+type flakyStore struct {
+	*store.Memory
+	err error
+}
+
+func (f *flakyStore) UpdateHash(ctx context.Context, key string, set map[string][]byte, incr map[string]int64, ttl time.Duration) (map[string][]byte, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.Memory.UpdateHash(ctx, key, set, incr, ttl)
+}
+
+func TestSharedSessionKeepsClientOnStoreError(t *testing.T) {
+	fake := useFakeClients(t)
+	st := &flakyStore{Memory: store.NewMemory()}
+	s, err := NewSharedSessionManager(newTestManager(t, 10, time.Hour), st, nil)
+	require.NoError(t, err)
+	require.NoError(t, s.Set("s1", testConfig))
+
+	st.err = errors.New("i/o timeout")
+	_, err = s.Get("s1")
+	assert.Error(t, err)
+
+	st.err = nil
+	_, err = s.Get("s1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, fake.createdCount(), "a store hiccup does not reconnect the session")
+	assert.Equal(t, 0, fake.closeCount(fake.client(0)))
+
+	// A session gone from the store releases its client
+	require.NoError(t, st.Delete(context.Background(), sessionPrefix+"s1"))
+	_, err = s.Get("s1")
+	assert.Error(t, err)
+	_, err = s.local.GetState("s1")
+	assert.Error(t, err)
+}
+
+func TestSharedSessionListCoversReplicas(t *testing.T) {
+	useFakeClients(t)
+	a, b := newReplicas(t)
+
+	require.NoError(t, a.Set("s1", testConfig))
+	require.NoError(t, b.Set("s2", testConfig))
+	require.NoError(t, b.Set("s3", testConfig))
+	require.NoError(t, b.Disconnect("s3"))
+
+	states := a.List()
+	require.Len(t, states, 2, "sessions of other replicas are listed, disconnected ones are not")
+	assert.Equal(t, "s1", states[0].SessionID)
+	assert.Equal(t, "s2", states[1].SessionID)
+}
+
+// storedFields returns the raw fields of a session as kept in the store
+func storedFields(t *testing.T, s *SharedSessionManager, sessionId string) string {
+	t.Helper()
+	fields, err := s.store.GetHash(context.Background(), sessionPrefix+sessionId)
+	require.NoError(t, err)
+	var raw strings.Builder
+	for _, value := range fields {
+		raw.Write(value)
+	}
+	return raw.String()
+}
+
+func TestSharedSessionProfileStoresName(t *testing.T) {
+	useFakeClients(t)
+	require.NoError(t, SetProfiles(map[string]ConnConfig{
+		"prod": {Address: "localhost:19530", Token: "root:Milvus", DBName: "default"},
+	}, "", true))
+	t.Cleanup(func() { SetProfiles(nil, "", true) })
+	a, b := newReplicas(t)
+
+	config, err := GetProfile("prod")
+	require.NoError(t, err)
+	require.NoError(t, a.Set("s1", config))
+	assert.NotContains(t, storedFields(t, a, "s1"), "root:Milvus")
+
+	state, err := b.GetState("s1")
+	require.NoError(t, err)
+	assert.Equal(t, "root:Milvus", state.ConnConfig.Token, "profiles are resolved on load")
+	assert.Equal(t, "prod", state.ConnConfig.Profile)
+}
+
+func TestSharedSessionCustomCredentials(t *testing.T) {
+	useFakeClients(t)
+	config := &ConnConfig{Address: "localhost:19530", APIKey: "secret-key"}
+
+	a, _ := newReplicas(t)
+	assert.Error(t, a.Set("s1", config), "credentials are not stored without an encryption key")
+	assert.Equal(t, 0, a.Size())
+	require.NoError(t, a.Set("s2", testConfig), "connections without credentials need no key")
+
+	a, b := newReplicasWithKey(t, bytes.Repeat([]byte{7}, 32))
+	require.NoError(t, a.Set("s1", config))
+	assert.NotContains(t, storedFields(t, a, "s1"), "secret-key")
+
+	state, err := b.GetState("s1")
+	require.NoError(t, err)
+	assert.Equal(t, "secret-key", state.ConnConfig.APIKey)
+
+	// A record copied to another session does not load
+	fields, err := a.store.GetHash(context.Background(), sessionPrefix+"s1")
+	require.NoError(t, err)
+	require.NoError(t, a.store.SetHash(context.Background(), sessionPrefix+"s3", fields, time.Hour))
+	_, err = b.GetState("s3")
+	assert.Error(t, err)
+}
+
+func TestSharedSessionConcurrentUpdates(t *testing.T) {
+	useFakeClients(t)
+	a, b := newReplicas(t)
+	require.NoError(t, a.Set("s1", testConfig))
+
+	const calls = 20
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := a.Get("s1")
+			assert.NoError(t, err)
+		}()
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, b.SetSessionMetadata("s1", fmt.Sprintf("key%d", i), i))
+		}(i)
+	}
+	wg.Wait()
+	require.NoError(t, b.UseDatabase("s1", "analytics"))
+
+	state, err := a.GetState("s1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(calls), state.AccessCount, "no access is lost")
+	assert.Len(t, state.Metadata, calls, "no metadata update is lost")
+	assert.Equal(t, "analytics", state.ConnConfig.DBName)
+}
@@ -0,0 +1,191 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often writes sweep expired items, so keys that are
+// never read again do not pile up
+const sweepInterval = time.Second
+
+// Memory is an in-process Store, replicas sharing one Memory store behave
+// like replicas sharing a Redis server
+type Memory struct {
+	mu    sync.Mutex
+	items map[string]memoryItem
+	// nextExpiry is the earliest expiry among the items, zero if none expires
+	nextExpiry time.Time
+	swept      time.Time
+}
+
+type memoryItem struct {
+	value   []byte
+	fields  map[string][]byte
+	expires time.Time
+}
+
+// NewMemory creates an empty in-process store
+func NewMemory() *Memory {
+	return &Memory{items: make(map[string]memoryItem)}
+}
+
+// item returns the live item under key, the caller must hold mu
+func (m *Memory) item(key string) (memoryItem, bool) {
+	item, ok := m.items[key]
+	if !ok {
+		return item, false
+	}
+	if !item.expires.IsZero() && time.Now().After(item.expires) {
+		delete(m.items, key)
+		return item, false
+	}
+	return item, true
+}
+
+// put stores item under key and sweeps expired items once one is due, the caller must hold mu
+func (m *Memory) put(key string, item memoryItem) {
+	m.items[key] = item
+	if !item.expires.IsZero() && (m.nextExpiry.IsZero() || item.expires.Before(m.nextExpiry)) {
+		m.nextExpiry = item.expires
+	}
+
+	now := time.Now()
+	if m.nextExpiry.IsZero() || now.Before(m.nextExpiry) || now.Sub(m.swept) < sweepInterval {
+		return
+	}
+	m.swept = now
+	m.nextExpiry = time.Time{}
+	for key, item := range m.items {
+		switch {
+		case item.expires.IsZero():
+		case now.After(item.expires):
+			delete(m.items, key)
+		case m.nextExpiry.IsZero() || item.expires.Before(m.nextExpiry):
+			m.nextExpiry = item.expires
+		}
+	}
+}
+
+// Len returns the number of items held, including expired ones not swept yet
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.items)
+}
+
+func expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+func copyFields(fields map[string][]byte) map[string][]byte {
+	copied := make(map[string][]byte, len(fields))
+	for field, value := range fields {
+		copied[field] = append([]byte(nil), value...)
+	}
+	return copied
+}
+
+func (m *Memory) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.item(key)
+	if !ok || item.fields != nil {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), item.value...), nil
+}
+
+func (m *Memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	item := memoryItem{value: append([]byte(nil), value...), expires: expiry(ttl)}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.put(key, item)
+	return nil
+}
+
+func (m *Memory) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.items, key)
+	return nil
+}
+
+func (m *Memory) Take(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.item(key)
+	if !ok || item.fields != nil {
+		return nil, ErrNotFound
+	}
+	delete(m.items, key)
+	return item.value, nil
+}
+
+func (m *Memory) Keys(ctx context.Context, prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var keys []string
+	for key := range m.items {
+		if _, ok := m.item(key); ok && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *Memory) GetHash(ctx context.Context, key string) (map[string][]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.item(key)
+	if !ok || item.fields == nil {
+		return nil, ErrNotFound
+	}
+	return copyFields(item.fields), nil
+}
+
+func (m *Memory) SetHash(ctx context.Context, key string, fields map[string][]byte, ttl time.Duration) error {
+	item := memoryItem{fields: copyFields(fields), expires: expiry(ttl)}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.put(key, item)
+	return nil
+}
+
+func (m *Memory) UpdateHash(ctx context.Context, key string, set map[string][]byte, incr map[string]int64, ttl time.Duration) (map[string][]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.item(key)
+	if !ok || item.fields == nil {
+		return nil, ErrNotFound
+	}
+	for field, value := range set {
+		item.fields[field] = append([]byte(nil), value...)
+	}
+	for field, delta := range incr {
+		current, _ := strconv.ParseInt(string(item.fields[field]), 10, 64)
+		item.fields[field] = []byte(strconv.FormatInt(current+delta, 10))
+	}
+	if ttl > 0 {
+		item.expires = expiry(ttl)
+	}
+	m.put(key, item)
+	return copyFields(item.fields), nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
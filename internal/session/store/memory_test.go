@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	_, err := m.Get(ctx, "s1")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	value := []byte(`{"session_id":"s1"}`)
+	require.NoError(t, m.Set(ctx, "s1", value, time.Minute))
+	value[0] = 'x'
+	got, err := m.Get(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, `{"session_id":"s1"}`, string(got), "the store keeps its own copy")
+
+	got, err = m.Take(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, `{"session_id":"s1"}`, string(got))
+	_, err = m.Take(ctx, "s1")
+	assert.ErrorIs(t, err, ErrNotFound, "only one caller takes the value")
+
+	require.NoError(t, m.Set(ctx, "s1", value, time.Minute))
+	require.NoError(t, m.Delete(ctx, "s1"))
+	require.NoError(t, m.Delete(ctx, "s1"))
+	_, err = m.Get(ctx, "s1")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, m.Set(ctx, "s2", value, time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	_, err = m.Get(ctx, "s2")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, m.Set(ctx, "session/s3", value, time.Minute))
+	require.NoError(t, m.SetHash(ctx, "session/s4", map[string][]byte{"record": value}, time.Minute))
+	require.NoError(t, m.Set(ctx, "confirm/t1", value, time.Minute))
+	keys, err := m.Keys(ctx, "session/")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"session/s3", "session/s4"}, keys)
+}
+
+func TestMemoryHash(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	_, err := m.UpdateHash(ctx, "s1", map[string][]byte{"database": []byte("analytics")}, nil, time.Minute)
+	assert.ErrorIs(t, err, ErrNotFound, "updates never create a hash")
+
+	require.NoError(t, m.SetHash(ctx, "s1", map[string][]byte{"record": []byte("{}"), "count": []byte("0")}, time.Minute))
+	fields, err := m.UpdateHash(ctx, "s1", map[string][]byte{"database": []byte("analytics")}, map[string]int64{"count": 2}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "analytics", string(fields["database"]))
+	assert.Equal(t, "2", string(fields["count"]))
+	assert.Equal(t, "{}", string(fields["record"]))
+
+	fields, err = m.GetHash(ctx, "s1")
+	require.NoError(t, err)
+	assert.Len(t, fields, 3)
+
+	require.NoError(t, m.SetHash(ctx, "s1", map[string][]byte{"record": []byte("{}")}, time.Minute))
+	fields, err = m.GetHash(ctx, "s1")
+	require.NoError(t, err)
+	assert.Len(t, fields, 1, "SetHash replaces the whole hash")
+}
@@ -0,0 +1,181 @@
+package store
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisOptions configures a Redis store, any server speaking the Redis protocol
+// such as Valkey or KeyDB works
+type RedisOptions struct {
+	Address   string
+	Username  string
+	Password  string
+	DB        int
+	KeyPrefix string
+	TLS       bool
+}
+
+// Redis is a Store backed by a Redis server
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedis connects to the Redis server and verifies it is reachable
+func NewRedis(opts RedisOptions) (*Redis, error) {
+	redisOpts := &redis.Options{
+		Addr:     opts.Address,
+		Username: opts.Username,
+		Password: opts.Password,
+		DB:       opts.DB,
+	}
+	if opts.TLS {
+		redisOpts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	client := redis.NewClient(redisOpts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", opts.Address, err)
+	}
+
+	return &Redis{client: client, prefix: opts.KeyPrefix}, nil
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, r.prefix+key, value, ttl).Err()
+}
+
+func (r *Redis) Delete(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.prefix+key).Err()
+}
+
+// Take needs Redis 6.2 or later for GETDEL
+func (r *Redis) Take(ctx context.Context, key string) ([]byte, error) {
+	value, err := r.client.GetDel(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+// Keys walks the keyspace with SCAN, so it does not block the server like KEYS
+func (r *Redis) Keys(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	seen := make(map[string]bool)
+	iter := r.client.Scan(ctx, 0, globEscaper.Replace(r.prefix+prefix)+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		// SCAN may return a key more than once
+		if key := iter.Val(); !seen[key] {
+			seen[key] = true
+			keys = append(keys, strings.TrimPrefix(key, r.prefix))
+		}
+	}
+	return keys, iter.Err()
+}
+
+// globEscaper escapes the characters SCAN MATCH patterns treat specially
+var globEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
+func (r *Redis) GetHash(ctx context.Context, key string) (map[string][]byte, error) {
+	values, err := r.client.HGetAll(ctx, r.prefix+key).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, ErrNotFound
+	}
+	return hashFields(values), nil
+}
+
+func (r *Redis) SetHash(ctx context.Context, key string, fields map[string][]byte, ttl time.Duration) error {
+	values := make([]interface{}, 0, 2*len(fields))
+	for field, value := range fields {
+		values = append(values, field, value)
+	}
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, r.prefix+key)
+		pipe.HSet(ctx, r.prefix+key, values...)
+		if ttl > 0 {
+			pipe.PExpire(ctx, r.prefix+key, ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+// updateHashScript updates an existing hash in one round trip
+// ARGV holds the TTL in milliseconds, the number of fields to set, the field/value
+// pairs to set and then the field/delta pairs to increment
+var updateHashScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+local i = 3
+for _ = 1, tonumber(ARGV[2]) do
+	redis.call('HSET', KEYS[1], ARGV[i], ARGV[i + 1])
+	i = i + 2
+end
+while i < #ARGV do
+	redis.call('HINCRBY', KEYS[1], ARGV[i], ARGV[i + 1])
+	i = i + 2
+end
+if tonumber(ARGV[1]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return redis.call('HGETALL', KEYS[1])
+`)
+
+func (r *Redis) UpdateHash(ctx context.Context, key string, set map[string][]byte, incr map[string]int64, ttl time.Duration) (map[string][]byte, error) {
+	args := []interface{}{ttl.Milliseconds(), len(set)}
+	for field, value := range set {
+		args = append(args, field, value)
+	}
+	for field, delta := range incr {
+		args = append(args, field, delta)
+	}
+
+	values, err := updateHashScript.Run(ctx, r.client, []string{r.prefix + key}, args...).StringSlice()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		fields[values[i]] = values[i+1]
+	}
+	return hashFields(fields), nil
+}
+
+func hashFields(values map[string]string) map[string][]byte {
+	fields := make(map[string][]byte, len(values))
+	for field, value := range values {
+		fields[field] = []byte(value)
+	}
+	return fields
+}
+
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
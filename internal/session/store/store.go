@@ -0,0 +1,35 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by Get when the key does not exist or has expired
+var ErrNotFound = errors.New("key not found")
+
+// Store persists serialized sessions outside the process, so any replica
+// behind a load balancer can serve a session created by another one
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value under key, replacing it and resetting its TTL
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key, deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+	// Take gets and deletes key at once, so of concurrent callers only one gets the value
+	Take(ctx context.Context, key string) ([]byte, error)
+	// Keys returns the live keys starting with prefix, in no particular order
+	Keys(ctx context.Context, prefix string) ([]string, error)
+
+	// Hashes keep the fields of a session separately, so replicas updating
+	// different fields never overwrite each other's changes
+	GetHash(ctx context.Context, key string) (map[string][]byte, error)
+	// SetHash replaces the hash under key with fields and resets its TTL
+	SetHash(ctx context.Context, key string, fields map[string][]byte, ttl time.Duration) error
+	// UpdateHash atomically sets and increments fields of the existing hash under key,
+	// resets its TTL and returns all its fields, or ErrNotFound if there is no such hash
+	UpdateHash(ctx context.Context, key string, set map[string][]byte, incr map[string]int64, ttl time.Duration) (map[string][]byte, error)
+
+	Close() error
+}
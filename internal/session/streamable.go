@@ -0,0 +1,98 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/sirupsen/logrus"
+)
+
+const streamableSessionPrefix = "mcp-session-"
+
+// StreamableSessionIdManager issues and validates Mcp-Session-Id values for the
+// streamable HTTP transport. Session state survives across individual POST requests and
+// GET notification streams, so a client may drop its stream and open a new one at will;
+// notifications sent in between are not replayed. Sessions are only torn down when the
+// client sends DELETE or the session expires.
+type StreamableSessionIdManager struct {
+	mu         sync.Mutex
+	terminated map[string]time.Time
+	retention  time.Duration
+}
+
+var _ server.SessionIdManager = (*StreamableSessionIdManager)(nil)
+
+// NewStreamableSessionIdManager creates a session ID manager backed by the global session manager
+func NewStreamableSessionIdManager() *StreamableSessionIdManager {
+	return &StreamableSessionIdManager{
+		terminated: make(map[string]time.Time),
+		retention:  managerOptions.DefaultTTL,
+	}
+}
+
+// Generate returns a new session ID for an initialize request
+func (m *StreamableSessionIdManager) Generate() string {
+	return streamableSessionPrefix + uuid.New().String()
+}
+
+// Validate checks the session ID format and whether the session was terminated
+// Sessions disconnected by an operator count as terminated, so clients start a new one
+func (m *StreamableSessionIdManager) Validate(sessionID string) (isTerminated bool, err error) {
+	if !strings.HasPrefix(sessionID, streamableSessionPrefix) {
+		return false, fmt.Errorf("invalid session id: %s", sessionID)
+	}
+	if _, err := uuid.Parse(strings.TrimPrefix(sessionID, streamableSessionPrefix)); err != nil {
+		return false, fmt.Errorf("invalid session id: %s", sessionID)
+	}
+	if GetSessionManager().IsDisconnected(sessionID) {
+		return true, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, isTerminated = m.terminated[sessionID]
+	return isTerminated, nil
+}
+
+// Terminate marks the session as terminated and releases its Milvus client
+func (m *StreamableSessionIdManager) Terminate(sessionID string) (isNotAllowed bool, err error) {
+	if _, err := m.Validate(sessionID); err != nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	now := time.Now()
+	m.terminated[sessionID] = now
+	// Terminated IDs only need to be remembered until a client could no longer
+	// plausibly reuse them
+	for id, at := range m.terminated {
+		if now.Sub(at) > m.retention {
+			delete(m.terminated, id)
+		}
+	}
+	m.mu.Unlock()
+
+	logrus.WithField("session_id", sessionID).Info("Session terminated by client")
+	unbindPrincipal(sessionID)
+
+	sessionManager := GetSessionManager()
+	if _, err := sessionManager.GetState(sessionID); err == nil {
+		if err := sessionManager.Remove(sessionID); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"session_id": sessionID,
+				"error":      err,
+			}).Warn("Failed to cleanup session")
+		}
+	}
+	return false, nil
+}
+
+// isStreamableSession reports whether the client session belongs to the streamable HTTP transport
+func isStreamableSession(sessionCli server.ClientSession) bool {
+	_, ok := sessionCli.(server.SessionWithStreamableHTTPConfig)
+	return ok
+}
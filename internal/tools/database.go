@@ -0,0 +1,30 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/tailabs/mcp-milvus/internal/session"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/milvus-io/milvus/client/v2/milvusclient"
+)
+
+// withDatabase adds the optional db_name argument to tools that work inside a database
+func withDatabase() mcp.ToolOption {
+	return mcp.WithString("db_name",
+		mcp.Description("Run this call against another database without switching the session (default: the session's current database)."),
+	)
+}
+
+// milvusClient returns the session's Milvus client for the database the call targets
+func milvusClient(ctx context.Context, request mcp.CallToolRequest) (*milvusclient.Client, error) {
+	sessionId := server.ClientSessionFromContext(ctx).SessionID()
+	dbName := request.GetString("db_name", "")
+
+	// Auth has already loaded the session for this call
+	if state, err := session.CallState(ctx, sessionId); err == nil && state.Client != nil && dbName == "" {
+		return state.Client, nil
+	}
+	return session.GetSessionManager().GetForDatabase(sessionId, dbName)
+}
@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/tailabs/mcp-milvus/internal/registry"
 	"github.com/tailabs/mcp-milvus/internal/session"
@@ -13,18 +14,34 @@ import (
 
 // NewMilvusConnectorTool returns a tool for connecting to Milvus with detailed parameters.
 func NewMilvusConnectorTool() mcp.Tool {
+	description := "Connect to a Milvus server instance with authentication and database selection."
+	if names := session.ProfileNames(); len(names) > 0 {
+		description += fmt.Sprintf(" Prefer a server-side connection profile, available profiles: %s.", strings.Join(names, ", "))
+	}
+	if !session.CustomConnectionsAllowed() {
+		description += " Connecting with a custom address is disabled on this server."
+	}
+
 	return mcp.NewTool("milvus_connector",
-		mcp.WithDescription("Connect to a Milvus server instance with authentication and database selection."),
+		mcp.WithDescription(description),
+		mcp.WithString("profile",
+			mcp.Description("Name of a server-side connection profile. When set, address and token are ignored."),
+		),
 		mcp.WithString("address",
-			mcp.Required(),
-			mcp.Description("The URI address of the Milvus server, e.g., 'http://localhost:19530'."),
+			mcp.Description("The URI address of the Milvus server, e.g., 'http://localhost:19530'. Required without a profile."),
 		),
 		mcp.WithString("token",
-			mcp.Description("Authentication credentials in the format 'username:password'."),
+			mcp.Description("Authentication credentials in the format 'username:password', or an API key."),
+		),
+		mcp.WithString("api_key",
+			mcp.Description("API key for managed clusters such as Zilliz Cloud. Cannot be combined with token."),
+		),
+		mcp.WithBoolean("enable_tls",
+			mcp.Description("Connect over TLS using the system certificate pool. Implied by an https:// address."),
 		),
 		mcp.WithString("db_name",
 			mcp.DefaultString("default"),
-			mcp.Description("The name of the database to connect to, e.g., 'default'."),
+			mcp.Description("The name of the database to connect to, e.g., 'default'. Overrides the profile's database."),
 		),
 	)
 }
@@ -36,11 +53,39 @@ func MilvusConnectorHandler(ctx context.Context, request mcp.CallToolRequest) (*
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
+	if connConfig.Profile != "" {
+		profile, err := session.GetProfile(connConfig.Profile)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if connConfig.DBName != "" {
+			profile.DBName = connConfig.DBName
+		}
+		connConfig = *profile
+	} else {
+		if !session.CustomConnectionsAllowed() {
+			return mcp.NewToolResultError(fmt.Sprintf("custom connections are disabled, use one of the connection profiles: %s",
+				strings.Join(session.ProfileNames(), ", "))), nil
+		}
+		if connConfig.Address == "" {
+			return mcp.NewToolResultError("address is required when no profile is given"), nil
+		}
+		// Certificate files live on the server, only profiles may reference them
+		connConfig.CACertFile = ""
+		connConfig.ClientCertFile = ""
+		connConfig.ClientKeyFile = ""
+		connConfig.ServerName = ""
+	}
+
 	sessionClient := server.ClientSessionFromContext(ctx)
 	if err := session.GetSessionManager().Set(sessionClient.SessionID(), &connConfig); err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	session.SetCallState(ctx, nil)
 
+	if connConfig.Profile != "" {
+		return mcp.NewToolResultText(fmt.Sprintf("Connected to Milvus successfully, profile: %s, database: %s", connConfig.Profile, connConfig.DBName)), nil
+	}
 	return mcp.NewToolResultText(fmt.Sprintf("Connected to Milvus successfully, database: %s", connConfig.DBName)), nil
 }
 
@@ -54,6 +99,10 @@ func (t *ConnectorTool) GetHandler() server.ToolHandlerFunc {
 	return MilvusConnectorHandler
 }
 
+func (t *ConnectorTool) GetAccess() registry.Access {
+	return registry.AccessRead
+}
+
 // Auto-register tool
 func init() {
 	registry.RegisterTool(&ConnectorTool{})
@@ -7,7 +7,6 @@ import (
 
 	"github.com/tailabs/mcp-milvus/internal/registry"
 	"github.com/tailabs/mcp-milvus/internal/schema"
-	"github.com/tailabs/mcp-milvus/internal/session"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -30,13 +29,13 @@ func NewMilvusCreateCollectionTool() mcp.Tool {
 		mcp.WithString("index_params",
 			mcp.Description("Optional index parameters as JSON array. Example: [{\"field_name\": \"vector\", \"index_type\": \"AUTOINDEX\", \"metric_type\": \"COSINE\", \"params\": {}}]"),
 		),
+		withDatabase(),
 	)
 }
 
 // MilvusCreateCollectionHandler handles the collection creation request
 func MilvusCreateCollectionHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sessionClient := server.ClientSessionFromContext(ctx)
-	cli, err := session.GetSessionManager().Get(sessionClient.SessionID())
+	cli, err := milvusClient(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -126,6 +125,10 @@ func (t *CreateCollectionTool) GetHandler() server.ToolHandlerFunc {
 	return MilvusCreateCollectionHandler
 }
 
+func (t *CreateCollectionTool) GetAccess() registry.Access {
+	return registry.AccessAdmin
+}
+
 func init() {
 	registry.RegisterTool(&CreateCollectionTool{})
 }
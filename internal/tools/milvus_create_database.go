@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	"github.com/tailabs/mcp-milvus/internal/registry"
-	"github.com/tailabs/mcp-milvus/internal/session"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -25,8 +24,7 @@ func NewMilvusCreateDatabaseTool() mcp.Tool {
 
 // MilvusCreateDatabaseHandler handles the database creation request
 func MilvusCreateDatabaseHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sessionClient := server.ClientSessionFromContext(ctx)
-	cli, err := session.GetSessionManager().Get(sessionClient.SessionID())
+	cli, err := milvusClient(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -56,6 +54,10 @@ func (t *CreateDatabaseTool) GetHandler() server.ToolHandlerFunc {
 	return MilvusCreateDatabaseHandler
 }
 
+func (t *CreateDatabaseTool) GetAccess() registry.Access {
+	return registry.AccessAdmin
+}
+
 func init() {
 	registry.RegisterTool(&CreateDatabaseTool{})
 }
@@ -8,7 +8,6 @@ import (
 	"fmt"
 
 	"github.com/tailabs/mcp-milvus/internal/registry"
-	"github.com/tailabs/mcp-milvus/internal/session"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -39,13 +38,13 @@ func NewMilvusCreateIndexTool() mcp.Tool {
 		mcp.WithString("params",
 			mcp.Description("Index parameters as JSON, e.g. {\"nlist\": 128}"),
 		),
+		withDatabase(),
 	)
 }
 
 // MilvusCreateIndexHandler handles the index creation request
 func MilvusCreateIndexHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sessionClient := server.ClientSessionFromContext(ctx)
-	cli, err := session.GetSessionManager().Get(sessionClient.SessionID())
+	cli, err := milvusClient(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -107,6 +106,10 @@ func (t *CreateIndexTool) GetHandler() server.ToolHandlerFunc {
 	return MilvusCreateIndexHandler
 }
 
+func (t *CreateIndexTool) GetAccess() registry.Access {
+	return registry.AccessAdmin
+}
+
 func init() {
 	registry.RegisterTool(&CreateIndexTool{})
 }
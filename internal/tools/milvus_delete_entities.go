@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	"github.com/tailabs/mcp-milvus/internal/registry"
-	"github.com/tailabs/mcp-milvus/internal/session"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -23,12 +22,12 @@ func NewMilvusDeleteEntitiesTool() mcp.Tool {
 			mcp.Required(),
 			mcp.Description("Filter expression to select entities to delete."),
 		),
+		withDatabase(),
 	)
 }
 
 func MilvusDeleteEntitiesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sessionClient := server.ClientSessionFromContext(ctx)
-	cli, err := session.GetSessionManager().Get(sessionClient.SessionID())
+	cli, err := milvusClient(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -60,6 +59,43 @@ func (t *DeleteEntitiesTool) GetHandler() server.ToolHandlerFunc {
 	return MilvusDeleteEntitiesHandler
 }
 
+func (t *DeleteEntitiesTool) GetAccess() registry.Access {
+	return registry.AccessWrite
+}
+
+// Preview counts the entities matching the delete filter
+func (t *DeleteEntitiesTool) Preview(ctx context.Context, request mcp.CallToolRequest) (string, error) {
+	cli, err := milvusClient(ctx, request)
+	if err != nil {
+		return "", err
+	}
+	collectionName, err := request.RequireString("collection_name")
+	if err != nil {
+		return "", err
+	}
+	filterExpr, err := request.RequireString("filter_expr")
+	if err != nil {
+		return "", err
+	}
+
+	// Counting needs a loaded collection, the delete itself does not
+	opt := milvusclient.NewQueryOption(collectionName).
+		WithFilter(filterExpr).
+		WithOutputFields("count(*)")
+	results, err := cli.Query(ctx, opt)
+	if err != nil {
+		return fmt.Sprintf("Deleting entities matching '%s' from collection '%s'. The number of matching entities is unknown: %v",
+			filterExpr, collectionName, err), nil
+	}
+	count, err := results.GetColumn("count(*)").Get(0)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Deleting entities matching '%s' from collection '%s' permanently deletes %v entities.",
+		filterExpr, collectionName, count), nil
+}
+
 func init() {
 	registry.RegisterTool(&DeleteEntitiesTool{})
 }
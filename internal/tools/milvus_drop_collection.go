@@ -2,14 +2,16 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/tailabs/mcp-milvus/internal/registry"
-	"github.com/tailabs/mcp-milvus/internal/session"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/milvus-io/milvus/client/v2/milvusclient"
+	"github.com/milvus-io/milvus/pkg/v2/util/merr"
 )
 
 // NewMilvusDropCollectionTool creates a new tool for dropping collections
@@ -20,13 +22,13 @@ func NewMilvusDropCollectionTool() mcp.Tool {
 			mcp.Required(),
 			mcp.Description("Name of the collection to drop."),
 		),
+		withDatabase(),
 	)
 }
 
 // MilvusDropCollectionHandler handles the collection dropping request
 func MilvusDropCollectionHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sessionClient := server.ClientSessionFromContext(ctx)
-	cli, err := session.GetSessionManager().Get(sessionClient.SessionID())
+	cli, err := milvusClient(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -56,6 +58,38 @@ func (t *DropCollectionTool) GetHandler() server.ToolHandlerFunc {
 	return MilvusDropCollectionHandler
 }
 
+func (t *DropCollectionTool) GetAccess() registry.Access {
+	return registry.AccessAdmin
+}
+
+// Preview reports how many entities and indexes dropping the collection destroys
+func (t *DropCollectionTool) Preview(ctx context.Context, request mcp.CallToolRequest) (string, error) {
+	cli, err := milvusClient(ctx, request)
+	if err != nil {
+		return "", err
+	}
+
+	collectionName, err := request.RequireString("collection_name")
+	if err != nil {
+		return "", err
+	}
+
+	stats, err := cli.GetCollectionStats(ctx, milvusclient.NewGetCollectionStatsOption(collectionName))
+	if err != nil {
+		return "", err
+	}
+	indexNames, err := cli.ListIndexes(ctx, milvusclient.NewListIndexOption(collectionName))
+	if err != nil && !errors.Is(err, merr.ErrIndexNotFound) {
+		return "", err
+	}
+
+	preview := fmt.Sprintf("Dropping collection '%s' permanently deletes %s entities", collectionName, stats["row_count"])
+	if len(indexNames) > 0 {
+		preview += fmt.Sprintf(" and %d index(es): %s", len(indexNames), strings.Join(indexNames, ", "))
+	}
+	return preview + ".", nil
+}
+
 func init() {
 	registry.RegisterTool(&DropCollectionTool{})
 }
@@ -4,9 +4,9 @@ package tools
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/tailabs/mcp-milvus/internal/registry"
-	"github.com/tailabs/mcp-milvus/internal/session"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -25,13 +25,13 @@ func NewMilvusDropIndexTool() mcp.Tool {
 			mcp.Required(),
 			mcp.Description("Name of the index to drop."),
 		),
+		withDatabase(),
 	)
 }
 
 // MilvusDropIndexHandler handles the index drop request
 func MilvusDropIndexHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sessionClient := server.ClientSessionFromContext(ctx)
-	cli, err := session.GetSessionManager().Get(sessionClient.SessionID())
+	cli, err := milvusClient(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -64,6 +64,36 @@ func (t *DropIndexTool) GetHandler() server.ToolHandlerFunc {
 	return MilvusDropIndexHandler
 }
 
+func (t *DropIndexTool) GetAccess() registry.Access {
+	return registry.AccessAdmin
+}
+
+// Preview describes the index that would be dropped
+func (t *DropIndexTool) Preview(ctx context.Context, request mcp.CallToolRequest) (string, error) {
+	cli, err := milvusClient(ctx, request)
+	if err != nil {
+		return "", err
+	}
+
+	collectionName, err := request.RequireString("collection_name")
+	if err != nil {
+		return "", err
+	}
+	indexName, err := request.RequireString("index_name")
+	if err != nil {
+		return "", err
+	}
+
+	indexDes, err := cli.DescribeIndex(ctx, milvusclient.NewDescribeIndexOption(collectionName, indexName))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Dropping index '%s' (%s, params: %v) of collection '%s'. "+
+		"Searches on the indexed field need a new index, which has to be rebuilt from scratch.",
+		indexName, indexDes.Index.IndexType(), indexDes.Params(), collectionName), nil
+}
+
 func init() {
 	registry.RegisterTool(&DropIndexTool{})
 }
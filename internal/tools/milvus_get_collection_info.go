@@ -7,7 +7,6 @@ import (
 	"fmt"
 
 	"github.com/tailabs/mcp-milvus/internal/registry"
-	"github.com/tailabs/mcp-milvus/internal/session"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -66,12 +65,12 @@ func NewMilvusGetCollectionInfoTool() mcp.Tool {
 			mcp.Required(),
 			mcp.Description("Name of collection to load."),
 		),
+		withDatabase(),
 	)
 }
 
 func MilvusGetCollectionInfoHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sessionClient := server.ClientSessionFromContext(ctx)
-	cli, err := session.GetSessionManager().Get(sessionClient.SessionID())
+	cli, err := milvusClient(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -200,6 +199,10 @@ func (t *GetCollectionInfoTool) GetHandler() server.ToolHandlerFunc {
 	return MilvusGetCollectionInfoHandler
 }
 
+func (t *GetCollectionInfoTool) GetAccess() registry.Access {
+	return registry.AccessRead
+}
+
 func init() {
 	registry.RegisterTool(&GetCollectionInfoTool{})
 }
@@ -0,0 +1,221 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tailabs/mcp-milvus/internal/metrics"
+	"github.com/tailabs/mcp-milvus/internal/registry"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/milvus-io/milvus/client/v2/entity"
+	"github.com/milvus-io/milvus/client/v2/milvusclient"
+)
+
+// annRequestArgs is one ANN sub-request of a hybrid search
+type annRequestArgs struct {
+	VectorField string          `json:"vector_field"`
+	Vector      json.RawMessage `json:"vector"`
+	Text        string          `json:"text"`
+	Params      searchParams    `json:"params"`
+	Filter      string          `json:"filter"`
+	Limit       int             `json:"limit"`
+}
+
+func NewMilvusHybridSearchTool() mcp.Tool {
+	return mcp.NewTool("milvus_hybrid_search",
+		mcp.WithDescription("Run several ANN searches on different vector fields of a collection, e.g. dense and BM25 sparse, and fuse their results with a reranker."),
+		mcp.WithString("collection_name",
+			mcp.Required(),
+			mcp.Description("Name of the collection to search."),
+		),
+		mcp.WithString("requests",
+			mcp.Required(),
+			mcp.Description(`ANN sub-requests as JSON array, each with "vector_field", either "vector" (encoded for the field type as in milvus_vector_search) or "text" (query text, searching the BM25 function output field if vector_field is omitted), and optional "params" (e.g. {"nprobe": 16}), "filter" and "limit" (default: the overall limit).`),
+		),
+		mcp.WithString("reranker",
+			mcp.Description("How to fuse the sub-request results: 'rrf' (reciprocal rank fusion) or 'weighted' (default: 'rrf')."),
+		),
+		mcp.WithString("rrf_k",
+			mcp.Description("Smoothing constant k of the rrf reranker (default: 60)."),
+		),
+		mcp.WithString("weights",
+			mcp.Description("Weights of the sub-requests for the weighted reranker as JSON array, one per request in order."),
+		),
+		mcp.WithString("limit",
+			mcp.Description("Maximum number of fused results (default: 5)."),
+		),
+		mcp.WithString("output_fields",
+			mcp.Description("Fields to include in results as JSON array."),
+		),
+		withDatabase(),
+	)
+}
+
+func MilvusHybridSearchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cli, err := milvusClient(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	collectionName, err := request.RequireString("collection_name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	requestsStr, err := request.RequireString("requests")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var subRequests []annRequestArgs
+	if err := json.Unmarshal([]byte(requestsStr), &subRequests); err != nil {
+		return mcp.NewToolResultError("Invalid requests JSON: " + err.Error()), nil
+	}
+	if len(subRequests) == 0 {
+		return mcp.NewToolResultError("requests must contain at least one ANN request"), nil
+	}
+
+	limit, err := strconv.Atoi(request.GetString("limit", "5"))
+	if err != nil {
+		limit = 5
+	}
+
+	// Queries are encoded for the type of the field each request searches
+	collection, err := describeCollection(ctx, cli, collectionName)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	annRequests := make([]*milvusclient.AnnRequest, 0, len(subRequests))
+	for i, sub := range subRequests {
+		annRequest, err := sub.annRequest(collection, limit)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("request %d: %v", i, err)), nil
+		}
+		annRequests = append(annRequests, annRequest)
+	}
+
+	reranker, err := hybridReranker(request, len(subRequests))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	opt := milvusclient.NewHybridSearchOption(collectionName, limit, annRequests...).WithReranker(reranker)
+
+	outputFieldsStr := request.GetString("output_fields", "")
+	if outputFieldsStr != "" {
+		var outputFields []string
+		if err := json.Unmarshal([]byte(outputFieldsStr), &outputFields); err != nil {
+			return mcp.NewToolResultError("Invalid output_fields JSON: " + err.Error()), nil
+		}
+		opt = opt.WithOutputFields(outputFields...)
+	}
+
+	results, err := cli.HybridSearch(ctx, opt)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	hits := []map[string]any{}
+	if len(results) > 0 {
+		hits = searchHits(results[0])
+	}
+	metrics.RowsReturned.WithLabelValues("milvus_hybrid_search").Add(float64(len(hits)))
+
+	outputResult, err := json.MarshalIndent(hits, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError("Failed to format search results: " + err.Error()), nil
+	}
+
+	output := fmt.Sprintf("Hybrid search results for collection '%s' (%d requests, %s reranker):\n\n", collectionName, len(subRequests), request.GetString("reranker", "rrf"))
+	output += fmt.Sprintf("Results: %s\n", string(outputResult))
+
+	return mcp.NewToolResultText(output), nil
+}
+
+// annRequest builds the ANN request, sub-requests without a limit use the overall one.
+// Text requests without a field search the output of the collection's BM25 function.
+func (a annRequestArgs) annRequest(collection *entity.Collection, limit int) (*milvusclient.AnnRequest, error) {
+	var vectorField string
+	var vector entity.Vector
+	switch {
+	case len(a.Vector) > 0 && a.Text != "":
+		return nil, fmt.Errorf("set either vector or text, not both")
+	case len(a.Vector) > 0:
+		field, err := searchField(collection, a.VectorField)
+		if err != nil {
+			return nil, err
+		}
+		if vector, err = encodeQueryVector(a.Vector, field); err != nil {
+			return nil, err
+		}
+		vectorField = field.Name
+	case a.Text != "":
+		field, err := bm25Field(collection, a.VectorField)
+		if err != nil {
+			return nil, err
+		}
+		vectorField = field
+		vector = entity.Text(a.Text)
+	default:
+		return nil, fmt.Errorf("vector or text is required")
+	}
+
+	if a.Limit > 0 {
+		limit = a.Limit
+	}
+	annRequest := milvusclient.NewAnnRequest(vectorField, limit, vector)
+	if len(a.Params) > 0 {
+		annRequest = annRequest.WithAnnParam(a.Params)
+	}
+	if a.Filter != "" {
+		annRequest = annRequest.WithFilter(a.Filter)
+	}
+	return annRequest, nil
+}
+
+// hybridReranker builds the reranker fusing the results of the sub-requests
+func hybridReranker(request mcp.CallToolRequest, requests int) (milvusclient.Reranker, error) {
+	switch strings.ToLower(request.GetString("reranker", "rrf")) {
+	case "rrf":
+		k, err := strconv.ParseFloat(request.GetString("rrf_k", "60"), 64)
+		if err != nil || k <= 0 {
+			return nil, fmt.Errorf("rrf_k must be a positive number")
+		}
+		return milvusclient.NewRRFReranker().WithK(k), nil
+	case "weighted":
+		var weights []float64
+		if err := json.Unmarshal([]byte(request.GetString("weights", "")), &weights); err != nil {
+			return nil, fmt.Errorf("weighted reranker requires weights as JSON array: %v", err)
+		}
+		if len(weights) != requests {
+			return nil, fmt.Errorf("weighted reranker requires one weight per request, got %d weights for %d requests", len(weights), requests)
+		}
+		return milvusclient.NewWeightedReranker(weights), nil
+	default:
+		return nil, fmt.Errorf("unknown reranker %q, use rrf or weighted", request.GetString("reranker", ""))
+	}
+}
+
+// Tool registrar
+type HybridSearchTool struct{}
+
+func (t *HybridSearchTool) GetTool() mcp.Tool {
+	return NewMilvusHybridSearchTool()
+}
+
+func (t *HybridSearchTool) GetHandler() server.ToolHandlerFunc {
+	return MilvusHybridSearchHandler
+}
+
+func (t *HybridSearchTool) GetAccess() registry.Access {
+	return registry.AccessRead
+}
+
+// Auto-register tool
+func init() {
+	registry.RegisterTool(&HybridSearchTool{})
+}
@@ -6,8 +6,8 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/tailabs/mcp-milvus/internal/metrics"
 	"github.com/tailabs/mcp-milvus/internal/registry"
-	"github.com/tailabs/mcp-milvus/internal/session"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -26,6 +26,7 @@ func NewMilvusInsertDataTool() mcp.Tool {
 			mcp.Required(),
 			mcp.Description("List of dictionaries, each representing a record."),
 		),
+		withDatabase(),
 	)
 }
 
@@ -313,8 +314,7 @@ func transformDataForCollection(ctx context.Context, cli *milvusclient.Client, c
 }
 
 func MilvusInsertDataHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sessionClient := server.ClientSessionFromContext(ctx)
-	cli, err := session.GetSessionManager().Get(sessionClient.SessionID())
+	cli, err := milvusClient(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -348,6 +348,7 @@ func MilvusInsertDataHandler(ctx context.Context, request mcp.CallToolRequest) (
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
+	metrics.RowsInserted.WithLabelValues("milvus_insert_data").Add(float64(insertResult.InsertCount))
 	return mcp.NewToolResultText(fmt.Sprintf("Inserted Count: %d", insertResult.InsertCount)), nil
 }
 
@@ -362,6 +363,10 @@ func (t *InsertDataTool) GetHandler() server.ToolHandlerFunc {
 	return MilvusInsertDataHandler
 }
 
+func (t *InsertDataTool) GetAccess() registry.Access {
+	return registry.AccessWrite
+}
+
 func init() {
 	registry.RegisterTool(&InsertDataTool{})
 }
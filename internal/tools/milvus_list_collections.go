@@ -5,23 +5,24 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/tailabs/mcp-milvus/internal/policy"
 	"github.com/tailabs/mcp-milvus/internal/registry"
-	"github.com/tailabs/mcp-milvus/internal/session"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/milvus-io/milvus/client/v2/milvusclient"
+	"github.com/samber/lo"
 )
 
 func NewMilvusListCollectionsTool() mcp.Tool {
 	return mcp.NewTool("milvus_list_collections",
 		mcp.WithDescription("List all collections in the database."),
+		withDatabase(),
 	)
 }
 
 func MilvusListCollectionsHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sessionClient := server.ClientSessionFromContext(ctx)
-	cli, err := session.GetSessionManager().Get(sessionClient.SessionID())
+	cli, err := milvusClient(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -30,6 +31,9 @@ func MilvusListCollectionsHandler(ctx context.Context, request mcp.CallToolReque
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	collections = lo.Filter(collections, func(name string, _ int) bool {
+		return policy.FromContext(ctx).AllowsCollection(name)
+	})
 
 	return mcp.NewToolResultText(fmt.Sprintf("Collections in database:\n%s", strings.Join(collections, ", "))), nil
 }
@@ -45,6 +49,10 @@ func (t *ListCollectionsTool) GetHandler() server.ToolHandlerFunc {
 	return MilvusListCollectionsHandler
 }
 
+func (t *ListCollectionsTool) GetAccess() registry.Access {
+	return registry.AccessRead
+}
+
 // Auto-register tool
 func init() {
 	registry.RegisterTool(&ListCollectionsTool{})
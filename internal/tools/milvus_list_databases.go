@@ -4,12 +4,13 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/tailabs/mcp-milvus/internal/policy"
 	"github.com/tailabs/mcp-milvus/internal/registry"
-	"github.com/tailabs/mcp-milvus/internal/session"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/milvus-io/milvus/client/v2/milvusclient"
+	"github.com/samber/lo"
 )
 
 // NewMilvusListDatabasesTool returns a tool for listing all databases in Milvus.
@@ -21,8 +22,7 @@ func NewMilvusListDatabasesTool() mcp.Tool {
 
 // MilvusListDatabasesHandler handles the milvus_list_databases tool call.
 func MilvusListDatabasesHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sessionClient := server.ClientSessionFromContext(ctx)
-	cli, err := session.GetSessionManager().Get(sessionClient.SessionID())
+	cli, err := milvusClient(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -32,6 +32,9 @@ func MilvusListDatabasesHandler(ctx context.Context, request mcp.CallToolRequest
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	dbs = lo.Filter(dbs, func(name string, _ int) bool {
+		return policy.FromContext(ctx).AllowsDatabase(name)
+	})
 	return mcp.NewToolResultText(fmt.Sprintf("Databases: %v", dbs)), nil
 }
 
@@ -46,6 +49,10 @@ func (t *ListDatabasesTool) GetHandler() server.ToolHandlerFunc {
 	return MilvusListDatabasesHandler
 }
 
+func (t *ListDatabasesTool) GetAccess() registry.Access {
+	return registry.AccessRead
+}
+
 func init() {
 	registry.RegisterTool(&ListDatabasesTool{})
 }
@@ -6,7 +6,6 @@ import (
 	"strconv"
 
 	"github.com/tailabs/mcp-milvus/internal/registry"
-	"github.com/tailabs/mcp-milvus/internal/session"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -23,12 +22,12 @@ func NewMilvusLoadCollectionTool() mcp.Tool {
 		mcp.WithString("replica_number",
 			mcp.Description("Number of replicas (default: 1)."),
 		),
+		withDatabase(),
 	)
 }
 
 func MilvusLoadCollectionHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sessionClient := server.ClientSessionFromContext(ctx)
-	cli, err := session.GetSessionManager().Get(sessionClient.SessionID())
+	cli, err := milvusClient(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -69,6 +68,10 @@ func (t *LoadCollectionTool) GetHandler() server.ToolHandlerFunc {
 	return MilvusLoadCollectionHandler
 }
 
+func (t *LoadCollectionTool) GetAccess() registry.Access {
+	return registry.AccessWrite
+}
+
 func init() {
 	registry.RegisterTool(&LoadCollectionTool{})
 }
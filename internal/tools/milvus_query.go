@@ -6,8 +6,8 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/tailabs/mcp-milvus/internal/metrics"
 	"github.com/tailabs/mcp-milvus/internal/registry"
-	"github.com/tailabs/mcp-milvus/internal/session"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -33,12 +33,12 @@ func NewMilvusQueryTool() mcp.Tool {
 		mcp.WithString("limit",
 			mcp.Description("Maximum number of results (default: 10)."),
 		),
+		withDatabase(),
 	)
 }
 
 func MilvusQueryHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sessionClient := server.ClientSessionFromContext(ctx)
-	cli, err := session.GetSessionManager().Get(sessionClient.SessionID())
+	cli, err := milvusClient(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -80,6 +80,7 @@ func MilvusQueryHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	metrics.RowsReturned.WithLabelValues("milvus_query").Add(float64(len(queryResultMaps)))
 
 	outputResult, err := json.MarshalIndent(queryResultMaps, "", "  ")
 	if err != nil {
@@ -131,6 +132,10 @@ func (t *QueryTool) GetHandler() server.ToolHandlerFunc {
 	return MilvusQueryHandler
 }
 
+func (t *QueryTool) GetAccess() registry.Access {
+	return registry.AccessRead
+}
+
 // Auto-register tool
 func init() {
 	registry.RegisterTool(&QueryTool{})
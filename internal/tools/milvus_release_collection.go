@@ -4,7 +4,6 @@ import (
 	"context"
 
 	"github.com/tailabs/mcp-milvus/internal/registry"
-	"github.com/tailabs/mcp-milvus/internal/session"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -22,6 +21,10 @@ func (t *ReleaseCollectionTool) GetHandler() server.ToolHandlerFunc {
 	return MilvusReleaseCollectionHandler
 }
 
+func (t *ReleaseCollectionTool) GetAccess() registry.Access {
+	return registry.AccessWrite
+}
+
 func init() {
 	registry.RegisterTool(&ReleaseCollectionTool{})
 }
@@ -33,12 +36,12 @@ func NewMilvusReleaseCollectionTool() mcp.Tool {
 			mcp.Required(),
 			mcp.Description("Name of collection to release."),
 		),
+		withDatabase(),
 	)
 }
 
 func MilvusReleaseCollectionHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sessionClient := server.ClientSessionFromContext(ctx)
-	cli, err := session.GetSessionManager().Get(sessionClient.SessionID())
+	cli, err := milvusClient(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
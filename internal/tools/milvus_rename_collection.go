@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	"github.com/tailabs/mcp-milvus/internal/registry"
-	"github.com/tailabs/mcp-milvus/internal/session"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -24,13 +23,13 @@ func NewMilvusRenameCollectionTool() mcp.Tool {
 			mcp.Required(),
 			mcp.Description("New name for the collection."),
 		),
+		withDatabase(),
 	)
 }
 
 // MilvusRenameCollectionHandler handles the collection renaming request
 func MilvusRenameCollectionHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sessionClient := server.ClientSessionFromContext(ctx)
-	cli, err := session.GetSessionManager().Get(sessionClient.SessionID())
+	cli, err := milvusClient(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -65,6 +64,10 @@ func (t *RenameCollectionTool) GetHandler() server.ToolHandlerFunc {
 	return MilvusRenameCollectionHandler
 }
 
+func (t *RenameCollectionTool) GetAccess() registry.Access {
+	return registry.AccessAdmin
+}
+
 func init() {
 	registry.RegisterTool(&RenameCollectionTool{})
 }
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tailabs/mcp-milvus/internal/registry"
+	"github.com/tailabs/mcp-milvus/internal/session"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// NewMilvusSessionInfoTool returns a tool describing the calling session.
+func NewMilvusSessionInfoTool() mcp.Tool {
+	return mcp.NewTool("milvus_session_info",
+		mcp.WithDescription("Show the current session: Milvus address, database, connection age and number of calls."),
+	)
+}
+
+// MilvusSessionInfoHandler handles the milvus_session_info tool call.
+func MilvusSessionInfoHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sessionClient := server.ClientSessionFromContext(ctx)
+	state, err := session.CallState(ctx, sessionClient.SessionID())
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	info := state.Info()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Session: %s\n", info.SessionID)
+	if info.Principal != "" {
+		fmt.Fprintf(&sb, "Principal: %s\n", info.Principal)
+	}
+	fmt.Fprintf(&sb, "Address: %s\n", info.Address)
+	if info.Profile != "" {
+		fmt.Fprintf(&sb, "Profile: %s\n", info.Profile)
+	}
+	fmt.Fprintf(&sb, "Database: %s\n", info.Database)
+	fmt.Fprintf(&sb, "Connected: %s (%s ago)\n", info.CreatedAt.Format(time.RFC3339), time.Since(info.CreatedAt).Round(time.Second))
+	fmt.Fprintf(&sb, "Calls: %d", info.AccessCount)
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// Tool registrar
+type SessionInfoTool struct{}
+
+func (t *SessionInfoTool) GetTool() mcp.Tool {
+	return NewMilvusSessionInfoTool()
+}
+
+func (t *SessionInfoTool) GetHandler() server.ToolHandlerFunc {
+	return MilvusSessionInfoHandler
+}
+
+func (t *SessionInfoTool) GetAccess() registry.Access {
+	return registry.AccessRead
+}
+
+func init() {
+	registry.RegisterTool(&SessionInfoTool{})
+}
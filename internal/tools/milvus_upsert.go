@@ -5,8 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/tailabs/mcp-milvus/internal/metrics"
 	"github.com/tailabs/mcp-milvus/internal/registry"
-	"github.com/tailabs/mcp-milvus/internal/session"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -28,13 +28,13 @@ func NewMilvusUpsertTool() mcp.Tool {
 		mcp.WithString("partition_name",
 			mcp.Description("Name of the partition to upsert data into (optional, defaults to default partition)."),
 		),
+		withDatabase(),
 	)
 }
 
 // MilvusUpsertHandler handles the upsert request
 func MilvusUpsertHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sessionClient := server.ClientSessionFromContext(ctx)
-	cli, err := session.GetSessionManager().Get(sessionClient.SessionID())
+	cli, err := milvusClient(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -78,6 +78,7 @@ func MilvusUpsertHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp
 		return mcp.NewToolResultError("Failed to upsert data: " + err.Error()), nil
 	}
 
+	metrics.RowsInserted.WithLabelValues("milvus_upsert").Add(float64(result.UpsertCount))
 	return mcp.NewToolResultText(fmt.Sprintf("Upserted %d records successfully. Upsert count: %d", len(transformedData), result.UpsertCount)), nil
 }
 
@@ -92,6 +93,10 @@ func (t *UpsertTool) GetHandler() server.ToolHandlerFunc {
 	return MilvusUpsertHandler
 }
 
+func (t *UpsertTool) GetAccess() registry.Access {
+	return registry.AccessWrite
+}
+
 func init() {
 	registry.RegisterTool(&UpsertTool{})
 }
@@ -9,7 +9,6 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
-	"github.com/milvus-io/milvus/client/v2/milvusclient"
 )
 
 // Tool registrar
@@ -23,6 +22,10 @@ func (t *UseDatabaseTool) GetHandler() server.ToolHandlerFunc {
 	return MilvusUseDatabaseHandler
 }
 
+func (t *UseDatabaseTool) GetAccess() registry.Access {
+	return registry.AccessRead
+}
+
 func init() {
 	registry.RegisterTool(&UseDatabaseTool{})
 }
@@ -38,22 +41,18 @@ func NewMilvusUseDatabaseTool() mcp.Tool {
 }
 
 func MilvusUseDatabaseHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sessionClient := server.ClientSessionFromContext(ctx)
-	cli, err := session.GetSessionManager().Get(sessionClient.SessionID())
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
-	}
-
 	databaseName, err := request.RequireString("database_name")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	opt := milvusclient.NewUseDatabaseOption(databaseName)
-	err = cli.UseDatabase(ctx, opt)
-	if err != nil {
+	// The client may be shared with other sessions, so the session moves to the
+	// client of the database instead of switching the client itself
+	sessionClient := server.ClientSessionFromContext(ctx)
+	if err := session.GetSessionManager().UseDatabase(sessionClient.SessionID(), databaseName); err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	session.SetCallState(ctx, nil)
 
 	return mcp.NewToolResultText(fmt.Sprintf("Successfully switched to database: %s", databaseName)), nil
 }
@@ -5,9 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 
+	"github.com/tailabs/mcp-milvus/internal/metrics"
 	"github.com/tailabs/mcp-milvus/internal/registry"
-	"github.com/tailabs/mcp-milvus/internal/session"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -17,17 +18,22 @@ import (
 
 func NewMilvusVectorSearchTool() mcp.Tool {
 	return mcp.NewTool("milvus_vector_search",
-		mcp.WithDescription("Perform vector similarity search on a collection."),
+		mcp.WithDescription("Perform vector similarity search on a collection, or full-text search with query text on collections with a BM25 function."),
 		mcp.WithString("collection_name",
 			mcp.Required(),
 			mcp.Description("Name of the collection to search."),
 		),
 		mcp.WithString("vector",
-			mcp.Required(),
-			mcp.Description("Query vector as JSON array."),
+			mcp.Description(`Query vector encoded for the searched field: JSON array of numbers for float, float16 and bfloat16 vectors, array of bits, array of bytes or base64 string for binary vectors, {"index": value} object for sparse vectors. One of vector, vectors or text is required.`),
+		),
+		mcp.WithString("vectors",
+			mcp.Description("Batch of query vectors as JSON array, each encoded like vector. Results are grouped per query in the same order."),
+		),
+		mcp.WithString("text",
+			mcp.Description("Query text for full-text (BM25) search, matched against the sparse output field of the collection's BM25 function."),
 		),
 		mcp.WithString("vector_field",
-			mcp.Description("Field containing vectors to search (default: 'vector')."),
+			mcp.Description("Field containing vectors to search (default: the collection's only vector field, or the BM25 output field for text)."),
 		),
 		mcp.WithString("limit",
 			mcp.Description("Maximum number of results (default: 5)."),
@@ -36,17 +42,17 @@ func NewMilvusVectorSearchTool() mcp.Tool {
 			mcp.Description("Fields to include in results as JSON array."),
 		),
 		mcp.WithString("metric_type",
-			mcp.Description("Distance metric (COSINE, L2, IP) (default: 'COSINE')."),
+			mcp.Description("Distance metric (COSINE, L2, IP, or BM25 for text), must match the metric the field is indexed with (default: the index's metric)."),
 		),
 		mcp.WithString("filter_expr",
 			mcp.Description("Optional filter expression."),
 		),
+		withDatabase(),
 	)
 }
 
 func MilvusVectorSearchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	sessionClient := server.ClientSessionFromContext(ctx)
-	cli, err := session.GetSessionManager().Get(sessionClient.SessionID())
+	cli, err := milvusClient(ctx, request)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -54,14 +60,57 @@ func MilvusVectorSearchHandler(ctx context.Context, request mcp.CallToolRequest)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
-	vectorStr, err := request.RequireString("vector")
+	vectorStr := request.GetString("vector", "")
+	vectorsStr := request.GetString("vectors", "")
+	text := request.GetString("text", "")
+	vectorField := request.GetString("vector_field", "")
+
+	var queries []json.RawMessage
+	switch {
+	case (vectorStr != "" && vectorsStr != "") || (vectorStr != "" && text != "") || (vectorsStr != "" && text != ""):
+		return mcp.NewToolResultError("set only one of vector, vectors and text"), nil
+	case vectorStr != "":
+		queries = []json.RawMessage{json.RawMessage(vectorStr)}
+	case vectorsStr != "":
+		if err := json.Unmarshal([]byte(vectorsStr), &queries); err != nil {
+			return mcp.NewToolResultError("Invalid vectors JSON: " + err.Error()), nil
+		}
+		if len(queries) == 0 {
+			return mcp.NewToolResultError("vectors must contain at least one query vector"), nil
+		}
+	case text == "":
+		return mcp.NewToolResultError("vector, vectors or text is required"), nil
+	}
+
+	// The query is encoded for the type of the searched field and validated before the search
+	collection, err := describeCollection(ctx, cli, collectionName)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	var vector []float32
-	if err := json.Unmarshal([]byte(vectorStr), &vector); err != nil {
-		return mcp.NewToolResultError("Invalid vector JSON: " + err.Error()), nil
+	var vectorData []entity.Vector
+	if text != "" {
+		// Text is only searchable through the sparse field a BM25 function fills from it
+		if vectorField, err = bm25Field(collection, vectorField); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		vectorData = []entity.Vector{entity.Text(text)}
+	} else {
+		field, err := searchField(collection, vectorField)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for i, query := range queries {
+			vector, err := encodeQueryVector(query, field)
+			if err != nil {
+				if vectorsStr != "" {
+					return mcp.NewToolResultError(fmt.Sprintf("query %d: %v", i, err)), nil
+				}
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			vectorData = append(vectorData, vector)
+		}
+		vectorField = field.Name
 	}
 
 	limitStr := request.GetString("limit", "5")
@@ -80,11 +129,10 @@ func MilvusVectorSearchHandler(ctx context.Context, request mcp.CallToolRequest)
 
 	filterExpr := request.GetString("filter_expr", "")
 
-	// Create vector data - Reference Python: data=[vector]
-	vectorData := []entity.Vector{entity.FloatVector(vector)}
-
-	// Use simplified search options
 	opt := milvusclient.NewSearchOption(collectionName, limit, vectorData)
+	if vectorField != "" {
+		opt = opt.WithANNSField(vectorField)
+	}
 
 	if len(outputFields) > 0 {
 		opt = opt.WithOutputFields(outputFields...)
@@ -94,48 +142,48 @@ func MilvusVectorSearchHandler(ctx context.Context, request mcp.CallToolRequest)
 		opt = opt.WithFilter(filterExpr)
 	}
 
+	if metricType := request.GetString("metric_type", ""); metricType != "" {
+		opt = opt.WithSearchParam("metric_type", strings.ToUpper(metricType))
+	}
+
 	results, err := cli.Search(ctx, opt)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	output := fmt.Sprintf("Vector search results for collection '%s':\n\n", collectionName)
-
-	// Simplified result processing
-	if len(results) > 0 {
-		resultSet := results[0]
-		resultCount := len(resultSet.Scores)
-		for i := 0; i < resultCount; i++ {
-			result := map[string]interface{}{}
-
-			// Get score
-			if i < len(resultSet.Scores) {
-				result["score"] = resultSet.Scores[i]
-			}
+	header := fmt.Sprintf("Vector search results for collection '%s':\n\n", collectionName)
+	switch {
+	case text != "":
+		header = fmt.Sprintf("Full-text search results for '%s' on field '%s' of collection '%s':\n\n", text, vectorField, collectionName)
+	case vectorsStr != "":
+		header = fmt.Sprintf("Vector search results for collection '%s' (%d queries):\n\n", collectionName, len(results))
+	}
+	return searchResult(header, results), nil
+}
 
-			// Get ID
-			if resultSet.IDs != nil {
-				if id, idErr := resultSet.IDs.Get(i); idErr == nil {
-					result["id"] = id
-				}
-			}
+// searchResult formats the hits grouped per query, Milvus returns one result set
+// per query in the order of the queries. A single query is a batch of one.
+func searchResult(header string, results []milvusclient.ResultSet) *mcp.CallToolResult {
+	type queryHits struct {
+		Query   int              `json:"query"`
+		Results []map[string]any `json:"results"`
+	}
 
-			// Get other fields
-			if resultSet.Fields != nil {
-				for _, column := range resultSet.Fields {
-					if value, valueErr := column.Get(i); valueErr == nil {
-						result[column.Name()] = value
-					}
-				}
-			}
+	groups := make([]queryHits, 0, len(results))
+	var total int
+	for i, resultSet := range results {
+		hits := searchHits(resultSet)
+		total += len(hits)
+		groups = append(groups, queryHits{Query: i, Results: hits})
+	}
+	metrics.RowsReturned.WithLabelValues("milvus_vector_search").Add(float64(total))
 
-			output += fmt.Sprintf("%v\n\n", result)
-		}
-	} else {
-		output += "No results found\n"
+	outputResult, err := json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		return mcp.NewToolResultError("Failed to format search results: " + err.Error())
 	}
 
-	return mcp.NewToolResultText(output), nil
+	return mcp.NewToolResultText(header + fmt.Sprintf("Results: %s\n", string(outputResult)))
 }
 
 // Tool registrar
@@ -149,6 +197,10 @@ func (t *VectorSearchTool) GetHandler() server.ToolHandlerFunc {
 	return MilvusVectorSearchHandler
 }
 
+func (t *VectorSearchTool) GetAccess() registry.Access {
+	return registry.AccessRead
+}
+
 // Auto-register tool
 func init() {
 	registry.RegisterTool(&VectorSearchTool{})
@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/milvus-io/milvus/client/v2/entity"
+	"github.com/milvus-io/milvus/client/v2/milvusclient"
+	"github.com/samber/lo"
+)
+
+// searchParams are index specific search parameters, e.g. {"nprobe": 16} or {"ef": 64}
+type searchParams map[string]any
+
+func (p searchParams) Params() map[string]any {
+	return p
+}
+
+// searchHits converts the hits of one search query to maps of score, id and output fields
+func searchHits(resultSet milvusclient.ResultSet) []map[string]any {
+	hits := make([]map[string]any, 0, len(resultSet.Scores))
+	for i := 0; i < len(resultSet.Scores); i++ {
+		hit := map[string]any{
+			"score": resultSet.Scores[i],
+		}
+		if resultSet.IDs != nil {
+			if id, err := resultSet.IDs.Get(i); err == nil {
+				hit["id"] = id
+			}
+		}
+		for _, column := range resultSet.Fields {
+			if value, err := column.Get(i); err == nil {
+				hit[column.Name()] = value
+			}
+		}
+		hits = append(hits, hit)
+	}
+	return hits
+}
+
+// describeCollection returns the schema of the collection a search targets
+func describeCollection(ctx context.Context, cli *milvusclient.Client, collectionName string) (*entity.Collection, error) {
+	collection, err := cli.DescribeCollection(ctx, milvusclient.NewDescribeCollectionOption(collectionName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe collection: %w", err)
+	}
+	return collection, nil
+}
+
+// bm25Field returns the sparse output field of a BM25 function that text queries search.
+// vectorField picks one of them, it is only required when the collection has several.
+func bm25Field(collection *entity.Collection, vectorField string) (string, error) {
+	var fields []string
+	for _, function := range collection.Schema.Functions {
+		if function.Type == entity.FunctionTypeBM25 {
+			fields = append(fields, function.OutputFieldNames...)
+		}
+	}
+
+	switch {
+	case len(fields) == 0:
+		return "", fmt.Errorf("collection %s has no BM25 function, search it with a vector instead of text", collection.Name)
+	case vectorField != "":
+		if !lo.Contains(fields, vectorField) {
+			return "", fmt.Errorf("field %s is not the output of a BM25 function, text can only search %s", vectorField, strings.Join(fields, ", "))
+		}
+		return vectorField, nil
+	case len(fields) > 1:
+		return "", fmt.Errorf("collection %s has several BM25 fields (%s), set vector_field", collection.Name, strings.Join(fields, ", "))
+	}
+	return fields[0], nil
+}
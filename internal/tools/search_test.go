@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/milvus-io/milvus/client/v2/column"
+	"github.com/milvus-io/milvus/client/v2/entity"
+	"github.com/milvus-io/milvus/client/v2/milvusclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bm25Collection(outputFields ...string) *entity.Collection {
+	schema := entity.NewSchema().WithName("docs")
+	for _, field := range outputFields {
+		schema.WithFunction(entity.NewFunction().
+			WithName(field + "_bm25").
+			WithType(entity.FunctionTypeBM25).
+			WithInputFields("text").
+			WithOutputFields(field))
+	}
+	return &entity.Collection{Name: "docs", Schema: schema}
+}
+
+func TestBM25Field(t *testing.T) {
+	field, err := bm25Field(bm25Collection("sparse"), "")
+	require.NoError(t, err)
+	assert.Equal(t, "sparse", field)
+
+	_, err = bm25Field(bm25Collection("sparse"), "dense")
+	assert.Error(t, err, "text cannot search a field without BM25 function")
+
+	_, err = bm25Field(bm25Collection(), "")
+	assert.Error(t, err)
+
+	_, err = bm25Field(bm25Collection("title_sparse", "body_sparse"), "")
+	assert.Error(t, err, "the field is ambiguous")
+	field, err = bm25Field(bm25Collection("title_sparse", "body_sparse"), "body_sparse")
+	require.NoError(t, err)
+	assert.Equal(t, "body_sparse", field)
+}
+
+func TestSearchResult(t *testing.T) {
+	results := []milvusclient.ResultSet{
+		{ResultCount: 2, Scores: []float32{0.9, 0.8}, IDs: column.NewColumnInt64("id", []int64{1, 2})},
+		{ResultCount: 0},
+		{ResultCount: 1, Scores: []float32{0.7}, IDs: column.NewColumnInt64("id", []int64{3})},
+	}
+
+	cr := searchResult("Vector search results:\n\n", results)
+	require.False(t, cr.IsError)
+	text := cr.Content[0].(mcp.TextContent).Text
+	_, encoded, found := strings.Cut(text, "Results: ")
+	require.True(t, found)
+
+	var groups []struct {
+		Query   int              `json:"query"`
+		Results []map[string]any `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(encoded), &groups))
+	require.Len(t, groups, 3)
+	for i, group := range groups {
+		assert.Equal(t, i, group.Query)
+	}
+	assert.Len(t, groups[0].Results, 2)
+	assert.Empty(t, groups[1].Results, "queries without hits keep their place")
+	assert.Equal(t, float64(3), groups[2].Results[0]["id"])
+}
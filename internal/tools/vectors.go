@@ -0,0 +1,172 @@
+package tools
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/milvus-io/milvus/client/v2/entity"
+)
+
+// searchVectorFieldTypes are the field types a query vector can search
+var searchVectorFieldTypes = []entity.FieldType{
+	entity.FieldTypeFloatVector,
+	entity.FieldTypeBinaryVector,
+	entity.FieldTypeFloat16Vector,
+	entity.FieldTypeBFloat16Vector,
+	entity.FieldTypeSparseVector,
+}
+
+// searchField returns the vector field a query vector searches, the only vector field of
+// the collection unless name picks one
+func searchField(collection *entity.Collection, name string) (*entity.Field, error) {
+	var fields []*entity.Field
+	for _, field := range collection.Schema.Fields {
+		for _, fieldType := range searchVectorFieldTypes {
+			if field.DataType == fieldType {
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if name == field.Name {
+			return field, nil
+		}
+		names = append(names, field.Name)
+	}
+
+	switch {
+	case name != "":
+		return nil, fmt.Errorf("collection %s has no vector field %s (vector fields: %s)", collection.Name, name, strings.Join(names, ", "))
+	case len(fields) == 0:
+		return nil, fmt.Errorf("collection %s has no vector field", collection.Name)
+	case len(fields) > 1:
+		return nil, fmt.Errorf("collection %s has several vector fields (%s), set vector_field", collection.Name, strings.Join(names, ", "))
+	}
+	return fields[0], nil
+}
+
+// encodeQueryVector encodes a JSON query vector for the type of the field it searches:
+//
+//	float, float16 and bfloat16 vectors  [0.1, 0.2, ...] with dim elements
+//	binary vectors                       dim bits [1, 0, ...], dim/8 bytes [255, 0, ...] or base64 of the bytes
+//	sparse vectors                       {"index": value, ...}
+func encodeQueryVector(raw json.RawMessage, field *entity.Field) (entity.Vector, error) {
+	switch field.DataType {
+	case entity.FieldTypeFloatVector, entity.FieldTypeFloat16Vector, entity.FieldTypeBFloat16Vector:
+		var vector []float32
+		if err := json.Unmarshal(raw, &vector); err != nil {
+			return nil, fmt.Errorf("field %s expects a vector as JSON array of numbers: %v", field.Name, err)
+		}
+		if err := checkDim(field, len(vector)); err != nil {
+			return nil, err
+		}
+		switch field.DataType {
+		case entity.FieldTypeFloat16Vector:
+			return entity.FloatVector(vector).ToFloat16Vector(), nil
+		case entity.FieldTypeBFloat16Vector:
+			return entity.FloatVector(vector).ToBFloat16Vector(), nil
+		}
+		return entity.FloatVector(vector), nil
+
+	case entity.FieldTypeBinaryVector:
+		return encodeBinaryVector(raw, field)
+
+	case entity.FieldTypeSparseVector:
+		return encodeSparseVector(raw, field)
+	}
+	return nil, fmt.Errorf("field %s has type %s, which cannot be searched with a vector", field.Name, field.DataType.Name())
+}
+
+func encodeBinaryVector(raw json.RawMessage, field *entity.Field) (entity.Vector, error) {
+	dim, err := fieldDim(field)
+	if err != nil {
+		return nil, err
+	}
+
+	var encoded string
+	if err := json.Unmarshal(raw, &encoded); err == nil {
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("field %s expects base64 for a binary vector given as string: %v", field.Name, err)
+		}
+		if len(data)*8 != dim {
+			return nil, fmt.Errorf("binary vector dimension mismatch: expected %d bits (%d bytes), got %d bytes", dim, dim/8, len(data))
+		}
+		return entity.BinaryVector(data), nil
+	}
+
+	var values []int
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, fmt.Errorf("field %s expects a binary vector as array of bits, array of bytes or base64 string: %v", field.Name, err)
+	}
+	switch len(values) {
+	case dim:
+		// Bits are packed most significant first, like numpy.packbits
+		data := make([]byte, dim/8)
+		for i, bit := range values {
+			if bit != 0 && bit != 1 {
+				return nil, fmt.Errorf("binary vector bit at index %d must be 0 or 1, got %d", i, bit)
+			}
+			data[i/8] |= byte(bit) << (7 - i%8)
+		}
+		return entity.BinaryVector(data), nil
+	case dim / 8:
+		data := make([]byte, len(values))
+		for i, value := range values {
+			if value < 0 || value > math.MaxUint8 {
+				return nil, fmt.Errorf("binary vector byte at index %d must be between 0 and 255, got %d", i, value)
+			}
+			data[i] = byte(value)
+		}
+		return entity.BinaryVector(data), nil
+	}
+	return nil, fmt.Errorf("binary vector dimension mismatch: expected %d bits or %d bytes, got %d elements", dim, dim/8, len(values))
+}
+
+func encodeSparseVector(raw json.RawMessage, field *entity.Field) (entity.Vector, error) {
+	var entries map[string]float32
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("field %s expects a sparse vector as JSON object of index to value, e.g. {\"17\": 0.5}: %v", field.Name, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("sparse vector for field %s is empty", field.Name)
+	}
+
+	positions := make([]uint32, 0, len(entries))
+	values := make([]float32, 0, len(entries))
+	for index, value := range entries {
+		position, err := strconv.ParseUint(index, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("sparse vector index %q must be a non-negative integer", index)
+		}
+		positions = append(positions, uint32(position))
+		values = append(values, value)
+	}
+	return entity.NewSliceSparseEmbedding(positions, values)
+}
+
+// checkDim validates the element count of a dense vector against the field's dim
+func checkDim(field *entity.Field, actual int) error {
+	dim, err := fieldDim(field)
+	if err != nil {
+		return err
+	}
+	if actual != dim {
+		return fmt.Errorf("vector dimension mismatch for field %s: expected %d, got %d elements", field.Name, dim, actual)
+	}
+	return nil
+}
+
+func fieldDim(field *entity.Field) (int, error) {
+	dim, err := field.GetDim()
+	if err != nil {
+		return 0, fmt.Errorf("field %s has no dimension: %v", field.Name, err)
+	}
+	return int(dim), nil
+}
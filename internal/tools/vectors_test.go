@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/milvus-io/milvus/client/v2/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func vectorField(name string, fieldType entity.FieldType, dim int64) *entity.Field {
+	field := entity.NewField().WithName(name).WithDataType(fieldType)
+	if dim > 0 {
+		field = field.WithDim(dim)
+	}
+	return field
+}
+
+func TestEncodeDenseVectors(t *testing.T) {
+	vector, err := encodeQueryVector(json.RawMessage(`[0.5, 1, -2]`), vectorField("dense", entity.FieldTypeFloatVector, 3))
+	require.NoError(t, err)
+	assert.Equal(t, entity.FloatVector{0.5, 1, -2}, vector)
+
+	vector, err = encodeQueryVector(json.RawMessage(`[0.5, 1, -2]`), vectorField("half", entity.FieldTypeFloat16Vector, 3))
+	require.NoError(t, err)
+	assert.IsType(t, entity.Float16Vector{}, vector)
+	assert.Equal(t, 3, vector.Dim())
+
+	vector, err = encodeQueryVector(json.RawMessage(`[0.5, 1, -2]`), vectorField("brain", entity.FieldTypeBFloat16Vector, 3))
+	require.NoError(t, err)
+	assert.IsType(t, entity.BFloat16Vector{}, vector)
+
+	_, err = encodeQueryVector(json.RawMessage(`[0.5, 1]`), vectorField("dense", entity.FieldTypeFloatVector, 3))
+	assert.ErrorContains(t, err, "expected 3, got 2")
+	_, err = encodeQueryVector(json.RawMessage(`{"1": 0.5}`), vectorField("dense", entity.FieldTypeFloatVector, 3))
+	assert.Error(t, err)
+}
+
+func TestEncodeBinaryVector(t *testing.T) {
+	field := vectorField("bits", entity.FieldTypeBinaryVector, 16)
+	expected := entity.BinaryVector{0b10000001, 0xff}
+
+	for name, raw := range map[string]string{
+		"bits":   `[1,0,0,0,0,0,0,1, 1,1,1,1,1,1,1,1]`,
+		"bytes":  `[129, 255]`,
+		"base64": `"gf8="`,
+	} {
+		vector, err := encodeQueryVector(json.RawMessage(raw), field)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, vector, name)
+	}
+
+	_, err := encodeQueryVector(json.RawMessage(`[1, 0, 1]`), field)
+	assert.ErrorContains(t, err, "dimension mismatch")
+	_, err = encodeQueryVector(json.RawMessage(`"gf8AAA=="`), field)
+	assert.ErrorContains(t, err, "dimension mismatch")
+	_, err = encodeQueryVector(json.RawMessage(`[2,0,0,0,0,0,0,0, 0,0,0,0,0,0,0,0]`), field)
+	assert.Error(t, err)
+}
+
+func TestEncodeSparseVector(t *testing.T) {
+	field := vectorField("sparse", entity.FieldTypeSparseVector, 0)
+
+	vector, err := encodeQueryVector(json.RawMessage(`{"42": 0.25, "7": 1.5}`), field)
+	require.NoError(t, err)
+	sparse, ok := vector.(entity.SparseEmbedding)
+	require.True(t, ok)
+	require.Equal(t, 2, sparse.Len())
+	position, value, _ := sparse.Get(0)
+	assert.Equal(t, uint32(7), position, "positions are sorted")
+	assert.Equal(t, float32(1.5), value)
+
+	_, err = encodeQueryVector(json.RawMessage(`{"-1": 0.5}`), field)
+	assert.Error(t, err)
+	_, err = encodeQueryVector(json.RawMessage(`{}`), field)
+	assert.Error(t, err)
+}
+
+func TestSearchField(t *testing.T) {
+	schema := entity.NewSchema().WithName("docs").
+		WithField(entity.NewField().WithName("id").WithDataType(entity.FieldTypeInt64).WithIsPrimaryKey(true)).
+		WithField(vectorField("dense", entity.FieldTypeFloatVector, 3))
+	collection := &entity.Collection{Name: "docs", Schema: schema}
+
+	field, err := searchField(collection, "")
+	require.NoError(t, err)
+	assert.Equal(t, "dense", field.Name)
+	_, err = searchField(collection, "id")
+	assert.Error(t, err, "scalar fields cannot be searched")
+
+	schema.WithField(vectorField("sparse", entity.FieldTypeSparseVector, 0))
+	_, err = searchField(collection, "")
+	assert.ErrorContains(t, err, "set vector_field")
+	field, err = searchField(collection, "sparse")
+	require.NoError(t, err)
+	assert.Equal(t, entity.FieldTypeSparseVector, field.DataType)
+}
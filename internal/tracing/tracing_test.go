@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useRecorder installs a tracer provider recording spans in memory for the test
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func TestExtract(t *testing.T) {
+	useRecorder(t)
+
+	meta := &mcp.Meta{AdditionalFields: map[string]any{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}}
+	parent := trace.SpanContextFromContext(Extract(context.Background(), meta))
+	assert.True(t, parent.IsRemote())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", parent.TraceID().String())
+
+	// Requests without metadata start a new trace
+	assert.False(t, trace.SpanContextFromContext(Extract(context.Background(), nil)).IsValid())
+	assert.False(t, trace.SpanContextFromContext(Extract(context.Background(), &mcp.Meta{})).IsValid())
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	recorder := useRecorder(t)
+	interceptor := UnaryClientInterceptor("localhost:19530")
+
+	ctx, parent := Tracer().Start(context.Background(), "tool milvus_query")
+	failing := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		return errors.New("unavailable")
+	}
+	err := interceptor(ctx, "/milvus.proto.milvus.MilvusService/Query", nil, nil, nil, failing)
+	assert.Error(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "milvus.Query", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
+
+func TestSetupFileExporter(t *testing.T) {
+	previous, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	shutdown, err := Setup(context.Background(), Options{Exporter: "file", File: path, SampleRatio: 1, ServiceName: "mcp-milvus"})
+	require.NoError(t, err)
+
+	_, span := Tracer().Start(context.Background(), "tool milvus_list_collections")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "tool milvus_list_collections")
+	assert.Contains(t, string(data), "mcp-milvus")
+
+	_, err = Setup(context.Background(), Options{Exporter: "zipkin"})
+	assert.Error(t, err)
+}